@@ -79,9 +79,33 @@ const (
 	// PatchStrategicMergeSourceRefNotReadyReason is used when source ref for patch strategic merge is not ready and there was no error.
 	PatchStrategicMergeSourceRefNotReadyReason = "PatchStrategicMergeSourceRefNotReady"
 
+	// KustomizeSourceRefNotReadyWithErrorReason is used when source ref for kustomize is not ready and there was an error.
+	KustomizeSourceRefNotReadyWithErrorReason = "KustomizeSourceRefNotReadyWithError"
+
+	// KustomizeSourceRefNotReadyReason is used when source ref for kustomize is not ready and there was no error.
+	KustomizeSourceRefNotReadyReason = "KustomizeSourceRefNotReady"
+
+	// SnapshotArtifactTypeMismatchReason is used when the content of a snapshot cannot be deployed with the requested template.
+	SnapshotArtifactTypeMismatchReason = "SnapshotArtifactTypeMismatch"
+
 	// SnapshotNameEmptyReason is used for a failure to generate a snapshot name.
 	SnapshotNameEmptyReason = "SnapshotNameEmpty"
 
+	// DecryptionFailedReason is used when encrypted values can't be decrypted.
+	DecryptionFailedReason = "DecryptionFailed"
+
+	// ConfigDataInvalidReason is used when the config data can't be parsed or fails validation.
+	ConfigDataInvalidReason = "ConfigDataInvalid"
+
+	// ValuesInvalidReason is used when the values of a configuration don't match the schema of the config data.
+	ValuesInvalidReason = "ValuesInvalid"
+
+	// SnapshotEncryptionFailedReason is used when snapshot content can't be encrypted or decrypted.
+	SnapshotEncryptionFailedReason = "SnapshotEncryptionFailed"
+
+	// EncryptedSnapshotNotSupportedReason is used when an encrypted snapshot would be exposed unencrypted.
+	EncryptedSnapshotNotSupportedReason = "EncryptedSnapshotNotSupported"
+
 	// TransferFailedReason is used when we fail to transfer a component.
 	TransferFailedReason = "TransferFailed"
 )
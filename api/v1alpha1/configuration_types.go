@@ -22,12 +22,22 @@ type Configuration struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec MutationSpec `json:"spec,omitempty"`
+	Spec ConfigurationSpec `json:"spec,omitempty"`
 
 	// +kubebuilder:default={"observedGeneration":-1}
 	Status MutationStatus `json:"status,omitempty"`
 }
 
+// ConfigurationSpec defines the desired state of a Configuration.
+type ConfigurationSpec struct {
+	MutationSpec `json:",inline"`
+
+	// Profile selects a profile of the config data, e.g. production. If it's empty, the profile is selected
+	// by the delivery.ocm.software/config-profile label of the namespace.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+}
+
 func (in *Configuration) GetVID() map[string]string {
 	metadata := make(map[string]string)
 	metadata[GroupVersion.Group+"/configuration_digest"] = in.Status.LatestSnapshotDigest
@@ -65,9 +75,9 @@ func (in Configuration) GetSnapshotName() string {
 	return in.Status.SnapshotName
 }
 
-// GetSpec returns the mutation spec for a Localization.
+// GetSpec returns the mutation spec for a Configuration.
 func (in *Configuration) GetSpec() *MutationSpec {
-	return &in.Spec
+	return &in.Spec.MutationSpec
 }
 
 // GetStatus returns the mutation status for a Localization.
@@ -16,6 +16,11 @@ const (
 	SourceNamespaceKey        = "source-namespace"
 	SourceArtifactChecksumKey = "source-artifact-checksum"
 	MutationObjectUUIDKey     = "mutation-object-uuid"
+	MutationStepsDigestKey    = "mutation-steps-digest"
+	KustomizationDigestKey    = "kustomization-digest"
+	PatchDigestKey            = "patch-digest"
+	HelmTemplateDigestKey     = "helm-template-digest"
+	ValuesDigestKey           = "values-digest"
 )
 
 // Externally defined extra identity keys.
@@ -26,6 +31,38 @@ const (
 	ResourceHelmChartVersion = "chartVersion"
 )
 
+// OCI manifest annotation keys describing the content of a snapshot.
+const (
+	ArtifactMediaTypeAnnotation        = "delivery.ocm.software/media-type"
+	ArtifactResourceTypeAnnotation     = "delivery.ocm.software/resource-type"
+	ArtifactResourceIdentityAnnotation = "delivery.ocm.software/resource-identity"
+	ArtifactComponentNameAnnotation    = "delivery.ocm.software/component-name"
+	ArtifactComponentVersionAnnotation = "delivery.ocm.software/component-version"
+	ArtifactSourceDigestAnnotation     = "delivery.ocm.software/source-digest"
+	ArtifactRenderedByAnnotation       = "delivery.ocm.software/rendered-by"
+	EncryptionKeyIDAnnotation          = "delivery.ocm.software/encryption-key-id"
+)
+
+// ConfigProfileLabel is the label of a namespace selecting the profile of the config data applied by the
+// configurations in the namespace which don't select a profile themselves.
+const ConfigProfileLabel = "delivery.ocm.software/config-profile"
+
+// PullSecretOwnerAnnotation is the annotation of a generated image pull secret holding the namespace and name
+// of the localization it was generated for. Secrets without it aren't overwritten.
+const PullSecretOwnerAnnotation = "delivery.ocm.software/pull-secret-owner"
+
+// Well-known resource types and media types.
+const (
+	// HelmChartResourceType is the OCM resource type of helm charts.
+	HelmChartResourceType = "helmChart"
+	// HelmChartMediaType is the layer media type used by helm for chart archives.
+	HelmChartMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	// HelmTemplateRenderer marks artifacts holding the manifests rendered from a helm chart.
+	HelmTemplateRenderer = "helmTemplate"
+	// EncryptedSnapshotMediaType is the layer media type of snapshots encrypted with a key from a secret.
+	EncryptedSnapshotMediaType = "application/vnd.ocm.software.snapshot.encrypted.v1"
+)
+
 // Log levels.
 const (
 	// LevelDebug defines the depth at witch debug information is displayed.
@@ -22,11 +22,21 @@ type Localization struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec MutationSpec `json:"spec,omitempty"`
+	Spec LocalizationSpec `json:"spec,omitempty"`
 	// +kubebuilder:default={"observedGeneration":-1}
 	Status MutationStatus `json:"status,omitempty"`
 }
 
+// LocalizationSpec defines the desired state of a Localization.
+type LocalizationSpec struct {
+	MutationSpec `json:",inline"`
+
+	// PullSecret generates a Secret with the credentials for the registries images were localized to and adds it
+	// to the imagePullSecrets of the workloads.
+	// +optional
+	PullSecret *PullSecret `json:"pullSecret,omitempty"`
+}
+
 func (in *Localization) GetVID() map[string]string {
 	metadata := make(map[string]string)
 	metadata[GroupVersion.Group+"/localization_digest"] = in.Status.LatestSnapshotDigest
@@ -66,7 +76,7 @@ func (in Localization) GetSnapshotName() string {
 
 // GetSpec returns the mutation spec for a Localization.
 func (in *Localization) GetSpec() *MutationSpec {
-	return &in.Spec
+	return &in.Spec.MutationSpec
 }
 
 // GetStatus returns the mutation status for a Localization.
@@ -30,18 +30,113 @@ type MutationSpec struct {
 	// +optional
 	Values *apiextensionsv1.JSON `json:"values,omitempty"`
 
+	// ValuesFrom is a single value source.
+	//
+	// Deprecated: use ValuesSources. If both are set, ValuesFrom is merged before the sources of ValuesSources.
 	// +optional
 	ValuesFrom *ValuesSource `json:"valuesFrom,omitempty"`
 
+	// ValuesSources is an ordered list of value sources. The values of the sources are merged in order,
+	// each according to its merge strategy, and Values are deep merged on top of them.
+	// +optional
+	ValuesSources []ValuesSource `json:"valuesSources,omitempty"`
+
 	// +optional
 	PatchStrategicMerge *PatchStrategicMerge `json:"patchStrategicMerge,omitempty"`
 
+	// Steps is an ordered list of mutations. Each step mutates the output of the step before it, the first
+	// step mutates the source. Steps can't be combined with ConfigRef and PatchStrategicMerge, which are
+	// applied in this order when Steps is empty.
+	// +optional
+	Steps []MutationStep `json:"steps,omitempty"`
+
+	// Decryption defines how values encrypted with SOPS are decrypted before they are merged.
+	// +optional
+	Decryption *Decryption `json:"decryption,omitempty"`
+
+	// Parameters are passed to the CUE programs of localization mappings as parameters, e.g. the name of an
+	// environment. Only used by localizations.
+	// +optional
+	Parameters *apiextensionsv1.JSON `json:"parameters,omitempty"`
+
+	// RegistryMirrors rewrite the locations of resources in a registry, or below a path of it, to a mirror before
+	// they are substituted, e.g. for an air-gapped cluster. For the same source, they take precedence over the
+	// mirrors of the controller. Only used by localizations.
+	// +optional
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
+
+	// SnapshotEncryption encrypts the content of the produced snapshot in the cache registry. It can't be
+	// deployed by a FluxDeployer, which exposes snapshots to Flux unencrypted.
+	// +optional
+	SnapshotEncryption *SnapshotEncryption `json:"snapshotEncryption,omitempty"`
+
 	// Suspend stops all operations on this object.
 	// +optional
 	Suspend bool `json:"suspend,omitempty"`
 }
 
-// ValuesSource provides access to values from an external Source such as a ConfigMap or GitRepository or ObjectReference.
+// RegistryMirror rewrites the repositories of a registry, or below a path of it, to a mirror, e.g. ghcr.io/org
+// to mirror.internal/team-a rewrites ghcr.io/org/podinfo:6.1.0 to mirror.internal/team-a/podinfo:6.1.0. If
+// several mirrors match, the one with the longest source is used.
+type RegistryMirror struct {
+	// Source is a registry optionally followed by a path, e.g. ghcr.io/org.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Source string `json:"source"`
+
+	// Mirror is the registry optionally followed by a path the repositories of the source are mirrored to.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Mirror string `json:"mirror"`
+}
+
+// PullSecret defines the image pull secret generated for the workloads of a localization. The registries are
+// the registry of the repository of the ComponentVersion and the registries of the mirrors, if the workloads
+// pull images from them. The Secret is only added to workloads deployed to the namespace of the localization,
+// workloads in other namespaces, set in their metadata or by the target namespace of a FluxDeployer, can't
+// reference it and are listed in the status instead.
+type PullSecret struct {
+	// Name is the name of the generated Secret, which is created in the namespace of the localization. Defaults to
+	// the name of the localization followed by -pull-secret.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// SecretRef references a Secret in the namespace of the localization with the credentials, either a
+	// .dockerconfigjson or a username and a password. Defaults to the Secret of the destination, or else of the
+	// repository, of the ComponentVersion of the config if it's in the namespace of the localization. The
+	// generated Secret is updated when it changes.
+	// +optional
+	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// MutationStep defines a single mutation of a pipeline. Exactly one of its fields must be set.
+type MutationStep struct {
+	// ConfigRef applies the localization or configuration rules of the referenced config data.
+	// +optional
+	ConfigRef *ObjectReference `json:"configRef,omitempty"`
+
+	// PatchStrategicMerge applies a strategic merge patch.
+	// +optional
+	PatchStrategicMerge *PatchStrategicMerge `json:"patchStrategicMerge,omitempty"`
+
+	// Kustomize builds a kustomization against the resource tree.
+	// +optional
+	Kustomize *Kustomize `json:"kustomize,omitempty"`
+
+	// JSONPatch applies RFC 6902 JSON patch operations to the targeted documents.
+	// +optional
+	JSONPatch *JSONPatch `json:"jsonPatch,omitempty"`
+
+	// YQ replaces the targeted documents with the result of a yq expression.
+	// +optional
+	YQ *YQExpression `json:"yq,omitempty"`
+
+	// HelmTemplate renders a helm chart into manifests.
+	// +optional
+	HelmTemplate *HelmTemplate `json:"helmTemplate,omitempty"`
+}
+
+// ValuesSource provides access to values from an external Source such as a ConfigMap, Secret or GitRepository or ObjectReference.
 // An optional subpath defines the path within the source from which the values should be resolved.
 type ValuesSource struct {
 	// +optional
@@ -49,7 +144,33 @@ type ValuesSource struct {
 	// +optional
 	ConfigMapSource *ConfigMapSource `json:"configMapSource,omitempty"`
 	// +optional
+	SecretSource *SecretSource `json:"secretSource,omitempty"`
+	// +optional
 	SourceRef *ObjectReference `json:"sourceRef,omitempty"`
+
+	// MergeStrategy defines how the values of this source are merged with the values of the sources before it.
+	// Replace overwrites top level keys, DeepMerge merges maps recursively and ListMergeByKey additionally merges
+	// lists of maps by the value of MergeKey. Defaults to DeepMerge.
+	// +kubebuilder:validation:Enum=Replace;DeepMerge;ListMergeByKey
+	// +optional
+	MergeStrategy string `json:"mergeStrategy,omitempty"`
+
+	// MergeKey identifies the elements of lists for the ListMergeByKey strategy. Defaults to name.
+	// +optional
+	MergeKey string `json:"mergeKey,omitempty"`
+}
+
+// Decryption defines the provider and keys used to decrypt values sources.
+type Decryption struct {
+	// Provider is the name of the decryption engine.
+	// +kubebuilder:validation:Enum=sops
+	// +required
+	Provider string `json:"provider"`
+
+	// SecretRef references a Secret in the namespace of the object containing age identities in entries
+	// ending with .agekey and armored PGP private keys in entries ending with .asc.
+	// +optional
+	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
 }
 
 type ConfigMapSource struct {
@@ -66,6 +187,22 @@ type ConfigMapSource struct {
 	Optional bool `json:"optional,omitempty"`
 }
 
+// SecretSource reads values from a key of a Secret in the namespace of the object. Values read from a
+// Secret are sensitive, they are masked in events, logs and conditions.
+type SecretSource struct {
+	// +required
+	SourceRef meta.LocalObjectReference `json:"sourceRef"`
+	// +required
+	Key string `json:"key"`
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+	// Optional marks this SecretSource as optional. When set, a not found
+	// error for the secret reference is ignored, but any Key, Subpath or
+	// transient error will still result in a reconciliation failure.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
 type FluxValuesSource struct {
 	// +required
 	SourceRef meta.NamespacedObjectKindReference `json:"sourceRef"`
@@ -100,12 +237,163 @@ type PatchStrategicMergeTarget struct {
 	Path string `json:"path"`
 }
 
+// Kustomize builds a kustomization against the resource tree. The files of the source are merged with the
+// resource tree, so the kustomization can refer to both. The output of the build replaces the resource tree.
+type Kustomize struct {
+	// Kustomization is an inline kustomization. It replaces a kustomization file in Path.
+	// Without it, Path must contain a kustomization file from the resource tree or the source.
+	// +optional
+	Kustomization *apiextensionsv1.JSON `json:"kustomization,omitempty"`
+
+	// SourceRef references a source with additional files for the kustomization, such as patches,
+	// components or a kustomization file.
+	// +optional
+	SourceRef *meta.NamespacedObjectKindReference `json:"sourceRef,omitempty"`
+
+	// Path is the directory of the kustomization, relative to the root of the resource tree.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// OutputPath is the file the built manifests are written to. Defaults to manifests.yaml.
+	// +optional
+	OutputPath string `json:"outputPath,omitempty"`
+}
+
+// PatchTarget selects the files and documents a patch applies to.
+type PatchTarget struct {
+	// File is the path of the files relative to the resource root. It may contain glob patterns, ** matches
+	// any number of directories.
+	// +required
+	File string `json:"file"`
+
+	// Document selects documents of YAML files by kind and name. Without it, every document is patched.
+	// +optional
+	Document *DocumentSelector `json:"document,omitempty"`
+}
+
+// DocumentSelector selects documents of a multi-document YAML file. Empty fields match any document. It
+// selects documents like the selector of localization rules in the config data.
+type DocumentSelector struct {
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// JSONPatch applies RFC 6902 JSON patch operations to YAML and JSON documents.
+type JSONPatch struct {
+	// +required
+	Target PatchTarget `json:"target"`
+
+	// +required
+	Operations []JSONPatchOperation `json:"operations"`
+}
+
+// JSONPatchOperation is a single RFC 6902 operation.
+type JSONPatchOperation struct {
+	// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	// +required
+	Op string `json:"op"`
+
+	// Path is a JSON pointer to the location the operation applies to.
+	// +required
+	Path string `json:"path"`
+
+	// From is a JSON pointer to the source location of move and copy operations.
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// Value is the value of add, replace and test operations.
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
+// YQExpression evaluates a yq expression on YAML and JSON documents. The result replaces the document,
+// so expressions usually update the document, e.g. `(.spec.replicas) = 3`.
+type YQExpression struct {
+	// +required
+	Target PatchTarget `json:"target"`
+
+	// +required
+	Expression string `json:"expression"`
+}
+
+// HelmTemplate renders a helm chart into plain manifests, like helm template. Dependencies of the chart
+// must be vendored, nothing is downloaded. The manifests replace the chart.
+type HelmTemplate struct {
+	// ReleaseName is the name of the release. Defaults to the name of the chart.
+	// +optional
+	ReleaseName string `json:"releaseName,omitempty"`
+
+	// Namespace is the namespace of the release. Defaults to the namespace of the object.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Values are deep merged on top of the values of ValuesSources.
+	// +optional
+	Values *apiextensionsv1.JSON `json:"values,omitempty"`
+
+	// ValuesSources is an ordered list of value sources, merged like the ValuesSources of the spec.
+	// +optional
+	ValuesSources []ValuesSource `json:"valuesSources,omitempty"`
+
+	// IncludeCRDs adds the CRDs of the chart to the manifests.
+	// +optional
+	IncludeCRDs bool `json:"includeCRDs,omitempty"`
+
+	// DisableHooks omits hooks from the manifests. Test hooks are always omitted.
+	// +optional
+	DisableHooks bool `json:"disableHooks,omitempty"`
+
+	// KubeVersion is the Kubernetes version used for capability checks.
+	// +optional
+	KubeVersion string `json:"kubeVersion,omitempty"`
+
+	// APIVersions are additional API versions used for capability checks.
+	// +optional
+	APIVersions []string `json:"apiVersions,omitempty"`
+
+	// OutputPath is the file the manifests are written to. Defaults to manifests.yaml.
+	// +optional
+	OutputPath string `json:"outputPath,omitempty"`
+}
+
 // GetRequeueAfter returns the duration after which the Localization must be
 // reconciled again.
 func (in MutationSpec) GetRequeueAfter() time.Duration {
 	return in.Interval.Duration
 }
 
+// GetValuesSources returns the value sources in the order they're merged. The deprecated ValuesFrom comes
+// first.
+func (in *MutationSpec) GetValuesSources() []ValuesSource {
+	if in.ValuesFrom == nil {
+		return in.ValuesSources
+	}
+
+	return append([]ValuesSource{*in.ValuesFrom}, in.ValuesSources...)
+}
+
+// GetSteps returns the ordered mutation steps. Without Steps, ConfigRef and PatchStrategicMerge form
+// the pipeline.
+func (in *MutationSpec) GetSteps() []MutationStep {
+	if len(in.Steps) > 0 {
+		return in.Steps
+	}
+
+	var steps []MutationStep
+	if in.ConfigRef != nil {
+		steps = append(steps, MutationStep{ConfigRef: in.ConfigRef})
+	}
+
+	if in.PatchStrategicMerge != nil {
+		steps = append(steps, MutationStep{PatchStrategicMerge: in.PatchStrategicMerge})
+	}
+
+	return steps
+}
+
 // MutationStatus defines a common status for Localizations and Configurations.
 type MutationStatus struct {
 	// ObservedGeneration is the last reconciled generation.
@@ -121,12 +409,147 @@ type MutationStatus struct {
 	// +optional
 	LatestSourceVersion string `json:"latestSourceVersion,omitempty"`
 
+	// LatestConfigVersion is the version of the config data. If several steps have a config ref, it's the
+	// version of the last one.
 	// +optional
 	LatestConfigVersion string `json:"latestConfigVersion,omitempty"`
 
+	// Profile is the profile of the config data which was applied.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+
 	// +optional
 	LatestPatchSourceVersion string `json:"latestPatchSourceVersio,omitempty"`
 
 	// +optional
 	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// MatchedFiles lists the files each configuration and localization rule was applied to.
+	// +optional
+	MatchedFiles []RuleMatch `json:"matchedFiles,omitempty"`
+
+	// SkippedRules lists the configuration and localization rules whose when condition was false.
+	// +optional
+	SkippedRules []SkippedRule `json:"skippedRules,omitempty"`
+
+	// Images lists the images found by the automatic localization, with the resource they were localized with
+	// if one matched.
+	// +optional
+	Images []ImageMatch `json:"images,omitempty"`
+
+	// PullSecret is the generated image pull secret.
+	// +optional
+	PullSecret *PullSecretStatus `json:"pullSecret,omitempty"`
+
+	// SchemaViolations lists the values of a configuration which don't match the schema of the config data.
+	// +optional
+	SchemaViolations []SchemaViolation `json:"schemaViolations,omitempty"`
+
+	// EffectiveValues are the configuration values after merging all value sources. They are omitted if
+	// any of the values is sensitive.
+	// +optional
+	EffectiveValues *apiextensionsv1.JSON `json:"effectiveValues,omitempty"`
+
+	// EffectiveValuesDigest is the digest of the effective configuration values.
+	// +optional
+	EffectiveValuesDigest string `json:"effectiveValuesDigest,omitempty"`
+}
+
+// RuleMatch contains the files a rule matched.
+type RuleMatch struct {
+	// Rule identifies the rule by its position in the config data, e.g. localization[0]. The rules of
+	// steps are prefixed with their step, e.g. steps[1].localization[0].
+	// +required
+	Rule string `json:"rule"`
+
+	// Pattern is the file pattern of the rule.
+	// +required
+	Pattern string `json:"pattern"`
+
+	// Files contains every file matched by the pattern and document selector of the rule.
+	// It's empty if the rule matched nothing.
+	// +optional
+	Files []string `json:"files,omitempty"`
+}
+
+// SkippedRule contains a rule which wasn't applied because of its condition.
+type SkippedRule struct {
+	// Rule identifies the rule by its position in the config data, e.g. configuration.rules[0]. The rules
+	// of steps are prefixed with their step, e.g. steps[1].configuration.rules[0].
+	// +required
+	Rule string `json:"rule"`
+
+	// When is the condition of the rule.
+	// +required
+	When string `json:"when"`
+}
+
+// ImageMatch contains an image of a manifest and the resource it was localized with.
+type ImageMatch struct {
+	// File is the file of the manifest.
+	// +required
+	File string `json:"file"`
+
+	// Kind is the kind of the document referencing the image.
+	// +required
+	Kind string `json:"kind"`
+
+	// Name is the name of the document referencing the image.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Path is the path of the image in the document, e.g. spec.template.spec.containers[0].image.
+	// +required
+	Path string `json:"path"`
+
+	// Image is the image the manifest referenced.
+	// +required
+	Image string `json:"image"`
+
+	// Resource is the name of the resource the image was localized with. It's empty if no resource matched.
+	// +optional
+	Resource string `json:"resource,omitempty"`
+
+	// Localized is the image it was localized to.
+	// +optional
+	Localized string `json:"localized,omitempty"`
+
+	// Message explains why no resource matched.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PullSecretStatus describes a generated image pull secret.
+type PullSecretStatus struct {
+	// Name is the name of the generated Secret.
+	// +required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the generated Secret.
+	// +required
+	Namespace string `json:"namespace"`
+
+	// SourceSecret is the namespace and name of the Secret the credentials were taken from.
+	// +required
+	SourceSecret string `json:"sourceSecret"`
+
+	// Registries are the registries the Secret has credentials for.
+	// +optional
+	Registries []string `json:"registries,omitempty"`
+
+	// SkippedWorkloads are the workloads pulling from the registries which are deployed to another namespace
+	// than the Secret, e.g. Deployment apps/podinfo. The Secret isn't added to them.
+	// +optional
+	SkippedWorkloads []string `json:"skippedWorkloads,omitempty"`
+}
+
+// SchemaViolation contains a value which doesn't match the schema.
+type SchemaViolation struct {
+	// Pointer is the JSON pointer of the value in the merged values, e.g. /image/tag. It's empty for the root.
+	// +optional
+	Pointer string `json:"pointer,omitempty"`
+
+	// Message describes the violation.
+	// +required
+	Message string `json:"message"`
 }
@@ -19,6 +19,11 @@ type ObjectReference struct {
 	// ResourceRef defines what resource to fetch.
 	// +optional
 	ResourceRef *ResourceReference `json:"resourceRef,omitempty"`
+
+	// Path is the path of a file in the artifact of a Flux source, e.g. a GitRepository or Bucket.
+	// It's required if config data is read from a Flux source.
+	// +optional
+	Path string `json:"path,omitempty"`
 }
 
 type ResourceReference struct {
@@ -1,6 +1,9 @@
 package v1alpha1
 
 import (
+	"encoding/json"
+
+	"github.com/fluxcd/pkg/apis/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -22,11 +25,29 @@ type SnapshotSpec struct {
 
 	Tag string `json:"tag"`
 
+	// Artifact describes the origin and the format of the snapshot content.
+	// +optional
+	Artifact *ArtifactMetadata `json:"artifact,omitempty"`
+
+	// Encryption references the key the snapshot content is encrypted with. Encrypted snapshots can only
+	// be read by the controller.
+	// +optional
+	Encryption *SnapshotEncryption `json:"encryption,omitempty"`
+
 	// Suspend stops all operations on this object.
 	// +optional
 	Suspend bool `json:"suspend,omitempty"`
 }
 
+// SnapshotEncryption configures the encryption of snapshot content in the cache registry. The content is
+// stored as an age message for the recipient of the identity of the secret.
+type SnapshotEncryption struct {
+	// SecretRef references a Secret in the namespace of the snapshot holding an age X25519 identity,
+	// as written by age-keygen, in its `identity.agekey` entry.
+	// +required
+	SecretRef meta.LocalObjectReference `json:"secretRef"`
+}
+
 // SnapshotStatus defines the observed state of Snapshot.
 type SnapshotStatus struct {
 	// +optional
@@ -40,6 +61,15 @@ type SnapshotStatus struct {
 	// +optional
 	LastReconciledTag string `json:"tag,omitempty"`
 
+	// Encryption references the key the content of the last reconciled digest is encrypted with. The
+	// content is read with it, the spec may already reference the key of content which isn't reconciled.
+	// +optional
+	LastReconciledEncryption *SnapshotEncryption `json:"encryption,omitempty"`
+
+	// Artifact describes the origin and the format of the last reconciled snapshot content.
+	// +optional
+	Artifact *ArtifactMetadata `json:"artifact,omitempty"`
+
 	// RepositoryURL has the concrete URL pointing to the local registry including the service name.
 	// +optional
 	RepositoryURL string `json:"repositoryURL,omitempty"`
@@ -49,6 +79,72 @@ type SnapshotStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
+// ArtifactMetadata describes the OCM resource a snapshot was created from. It is stored on the
+// Snapshot and as annotations on the OCI manifest that is pushed into the cache.
+type ArtifactMetadata struct {
+	// MediaType is the media type of the original resource.
+	// +optional
+	MediaType string `json:"mediaType,omitempty"`
+
+	// ResourceType is the OCM type of the original resource, e.g. helmChart or ociImage.
+	// +optional
+	ResourceType string `json:"resourceType,omitempty"`
+
+	// ResourceIdentity is the OCM identity of the original resource.
+	// +optional
+	ResourceIdentity ocmmetav1.Identity `json:"resourceIdentity,omitempty"`
+
+	// ComponentName is the name of the component the resource belongs to.
+	// +optional
+	ComponentName string `json:"componentName,omitempty"`
+
+	// ComponentVersion is the version of the component the resource belongs to.
+	// +optional
+	ComponentVersion string `json:"componentVersion,omitempty"`
+
+	// SourceDigest is the digest of the resource as recorded in the component descriptor.
+	// +optional
+	SourceDigest string `json:"sourceDigest,omitempty"`
+
+	// RenderedBy names the mutation which rendered the resource into plain manifests, e.g. helmTemplate.
+	// +optional
+	RenderedBy string `json:"renderedBy,omitempty"`
+}
+
+// IsHelmChart returns whether the artifact contains a helm chart. Rendered charts contain manifests.
+func (in *ArtifactMetadata) IsHelmChart() bool {
+	return in.RenderedBy == "" && (in.ResourceType == HelmChartResourceType || in.MediaType == HelmChartMediaType)
+}
+
+// Annotations returns the metadata as OCI manifest annotations. Empty values are omitted.
+func (in *ArtifactMetadata) Annotations() map[string]string {
+	annotations := make(map[string]string)
+	if in == nil {
+		return annotations
+	}
+
+	for k, v := range map[string]string{
+		ArtifactMediaTypeAnnotation:        in.MediaType,
+		ArtifactResourceTypeAnnotation:     in.ResourceType,
+		ArtifactComponentNameAnnotation:    in.ComponentName,
+		ArtifactComponentVersionAnnotation: in.ComponentVersion,
+		ArtifactSourceDigestAnnotation:     in.SourceDigest,
+		ArtifactRenderedByAnnotation:       in.RenderedBy,
+	} {
+		if v != "" {
+			annotations[k] = v
+		}
+	}
+
+	if len(in.ResourceIdentity) > 0 {
+		// a map of strings always marshals successfully
+		identity, _ := json.Marshal(in.ResourceIdentity)
+		annotations[ArtifactResourceIdentityAnnotation] = string(identity)
+	}
+
+	return annotations
+}
+
 func (in *Snapshot) GetVID() map[string]string {
 	metadata := make(map[string]string)
 	metadata[GroupVersion.Group+"/snapshot_digest"] = in.Status.LastReconciledDigest
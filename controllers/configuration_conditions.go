@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/component"
+	"github.com/open-component-model/ocm-controller/pkg/engine"
+)
+
+// ruleConditions evaluates the when conditions of rules and records the rules they skip. The scope of the
+// conditions is only built once a rule has a condition.
+type ruleConditions struct {
+	scope     func() (map[string]any, error)
+	condition *engine.Condition
+	skipped   []v1alpha1.SkippedRule
+}
+
+// newRuleConditions returns the conditions of the rules of the config data referenced by configRef.
+func (m *MutationReconcileLooper) newRuleConditions(
+	ctx context.Context,
+	spec *v1alpha1.MutationSpec,
+	configRef *v1alpha1.ObjectReference,
+	mergedValues map[string]any,
+) *ruleConditions {
+	return &ruleConditions{
+		scope: func() (map[string]any, error) {
+			return m.ruleScope(ctx, spec, configRef, mergedValues)
+		},
+	}
+}
+
+// applies returns whether the rule applies. Rules without a condition always apply.
+func (r *ruleConditions) applies(rule, when string) (bool, error) {
+	if r == nil || when == "" {
+		return true, nil
+	}
+
+	if r.condition == nil {
+		scope, err := r.scope()
+		if err != nil {
+			return false, fmt.Errorf("failed to build scope of conditions: %w", err)
+		}
+
+		if r.condition, err = engine.NewCondition(scope); err != nil {
+			return false, err
+		}
+	}
+
+	ok, err := r.condition.Evaluate(when)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate condition of rule %s: %w", rule, err)
+	}
+
+	if !ok {
+		r.skipped = append(r.skipped, v1alpha1.SkippedRule{Rule: rule, When: when})
+	}
+
+	return ok, nil
+}
+
+// skippedRules returns the rules skipped so far.
+func (r *ruleConditions) skippedRules() []v1alpha1.SkippedRule {
+	if r == nil {
+		return nil
+	}
+
+	return r.skipped
+}
+
+// ruleScope returns the values, the source and, if the config data belongs to a component version, its
+// component descriptor.
+func (m *MutationReconcileLooper) ruleScope(
+	ctx context.Context,
+	spec *v1alpha1.MutationSpec,
+	configRef *v1alpha1.ObjectReference,
+	mergedValues map[string]any,
+) (map[string]any, error) {
+	if mergedValues == nil {
+		mergedValues = map[string]any{}
+	}
+
+	sourceID, err := m.getIdentity(ctx, &spec.SourceRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity for source ref: %w", err)
+	}
+
+	scope := map[string]any{
+		"values": mergedValues,
+		"source": map[string]any{
+			"componentName":    sourceID[v1alpha1.ComponentNameKey],
+			"componentVersion": sourceID[v1alpha1.ComponentVersionKey],
+			"resourceName":     sourceID[v1alpha1.ResourceNameKey],
+			"resourceVersion":  sourceID[v1alpha1.ResourceVersionKey],
+		},
+	}
+
+	if configRef.Kind != v1alpha1.ComponentVersionKind {
+		return scope, nil
+	}
+
+	cv, err := m.getComponentVersion(ctx, configRef)
+	if err != nil {
+		return nil, err
+	}
+
+	cd, err := component.GetComponentDescriptor(ctx, m.Client, nil, cv.Status.ComponentDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get component descriptor: %w", err)
+	}
+
+	if cd != nil {
+		scope["component"] = cd.Spec
+	}
+
+	return scope, nil
+}
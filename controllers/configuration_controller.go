@@ -14,6 +14,7 @@ import (
 	rreconcile "github.com/fluxcd/pkg/runtime/reconcile"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	mh "github.com/open-component-model/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -31,10 +32,13 @@ import (
 
 	"github.com/open-component-model/ocm-controller/api/v1alpha1"
 	"github.com/open-component-model/ocm-controller/pkg/cache"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
 	"github.com/open-component-model/ocm-controller/pkg/metrics"
 	"github.com/open-component-model/ocm-controller/pkg/ocm"
+	"github.com/open-component-model/ocm-controller/pkg/redact"
 	"github.com/open-component-model/ocm-controller/pkg/snapshot"
 	"github.com/open-component-model/ocm-controller/pkg/status"
+	"github.com/open-component-model/ocm-controller/pkg/values"
 )
 
 // ConfigurationReconciler reconciles a Configuration object.
@@ -55,7 +59,9 @@ type ConfigurationReconciler struct {
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=configurations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=configurations/finalizers,verbs=update
 
-//+kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=gitrepositories;buckets;ocirepositories,verbs=get;list;watch
+//+kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=gitrepositories;buckets;ocirepositories;helmcharts,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // SetupWithManager sets up the controller with the Manager.
@@ -89,15 +95,21 @@ func (r *ConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return nil
 		}
 
-		if cfg.Spec.ConfigRef == nil {
-			return nil
-		}
-		ns := cfg.Spec.ConfigRef.Namespace
-		if ns == "" {
-			ns = cfg.GetNamespace()
+		var keys []string
+		for _, step := range cfg.Spec.GetSteps() {
+			if step.ConfigRef == nil {
+				continue
+			}
+
+			ns := step.ConfigRef.Namespace
+			if ns == "" {
+				ns = cfg.GetNamespace()
+			}
+
+			keys = append(keys, fmt.Sprintf("%s/%s", ns, step.ConfigRef.Name))
 		}
 
-		return []string{fmt.Sprintf("%s/%s", ns, cfg.Spec.ConfigRef.Name)}
+		return keys
 	}); err != nil {
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
@@ -108,15 +120,27 @@ func (r *ConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return nil
 		}
 
-		if cfg.Spec.PatchStrategicMerge == nil {
-			return nil
-		}
-		ns := cfg.Spec.PatchStrategicMerge.Source.SourceRef.Namespace
-		if ns == "" {
-			ns = cfg.GetNamespace()
+		var keys []string
+		for _, step := range cfg.Spec.GetSteps() {
+			var ref *meta.NamespacedObjectKindReference
+			switch {
+			case step.PatchStrategicMerge != nil:
+				ref = &step.PatchStrategicMerge.Source.SourceRef
+			case step.Kustomize != nil && step.Kustomize.SourceRef != nil:
+				ref = step.Kustomize.SourceRef
+			default:
+				continue
+			}
+
+			ns := ref.Namespace
+			if ns == "" {
+				ns = cfg.GetNamespace()
+			}
+
+			keys = append(keys, fmt.Sprintf("%s/%s", ns, ref.Name))
 		}
 
-		return []string{fmt.Sprintf("%s/%s", ns, cfg.Spec.PatchStrategicMerge.Source.SourceRef.Name)}
+		return keys
 	}); err != nil {
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
@@ -127,23 +151,32 @@ func (r *ConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return nil
 		}
 
-		if cfg.Spec.ValuesFrom == nil {
-			return nil
-		}
-		if cfg.Spec.ValuesFrom.FluxSource == nil {
-			return nil
+		sources := append([]v1alpha1.ValuesSource{}, cfg.Spec.GetValuesSources()...)
+		for _, step := range cfg.Spec.GetSteps() {
+			if step.HelmTemplate != nil {
+				sources = append(sources, step.HelmTemplate.ValuesSources...)
+			}
 		}
-		ns := cfg.Spec.ValuesFrom.FluxSource.SourceRef.Namespace
-		if ns == "" {
-			ns = cfg.GetNamespace()
+
+		var keys []string
+		for _, source := range sources {
+			if source.FluxSource == nil {
+				continue
+			}
+			ns := source.FluxSource.SourceRef.Namespace
+			if ns == "" {
+				ns = cfg.GetNamespace()
+			}
+
+			keys = append(keys, fmt.Sprintf("%s/%s", ns, source.FluxSource.SourceRef.Name))
 		}
 
-		return []string{fmt.Sprintf("%s/%s", ns, cfg.Spec.ValuesFrom.FluxSource.SourceRef.Name)}
+		return keys
 	}); err != nil {
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Configuration{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(
 			&v1alpha1.ComponentVersion{},
@@ -156,11 +189,21 @@ func (r *ConfigurationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			builder.WithPredicates(SnapshotDigestChangedPredicate{}),
 		).
 		Watches(
-			&sourcev1.GitRepository{},
-			handler.EnqueueRequestsFromMapFunc(r.findObjectsForGitRepository(patchSourceKey, valuesSourceKey)),
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNamespace),
+			builder.OnlyMetadata,
+			builder.WithPredicates(ConfigProfileLabelChangedPredicate{}),
+		)
+
+	for _, source := range fluxSourceObjects() {
+		b = b.Watches(
+			source,
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSource(patchSourceKey, valuesSourceKey, configKey)),
 			builder.WithPredicates(SourceRevisionChangePredicate{}),
-		).
-		Complete(r)
+		)
+	}
+
+	return b.Complete(r)
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -221,54 +264,81 @@ func (r *ConfigurationReconciler) Reconcile(
 		return ctrl.Result{}, nil
 	}
 
-	if obj.Spec.ConfigRef != nil {
-		ready, err := r.checkReadiness(ctx, obj.GetNamespace(), obj.Spec.ConfigRef)
-		if err != nil {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.ConfigRefNotReadyWithErrorReason,
-				fmt.Sprintf("config ref not yet ready with error: %s: %s", obj.Spec.ConfigRef.Name, err),
-			)
-
-			// we are watching the source object which should re-trigger the reconcile loop
-			return ctrl.Result{}, nil
+	for _, step := range obj.Spec.GetSteps() {
+		if step.ConfigRef != nil {
+			ready, err := r.checkReadiness(ctx, obj.GetNamespace(), step.ConfigRef)
+			if err != nil {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.ConfigRefNotReadyWithErrorReason,
+					fmt.Sprintf("config ref not yet ready with error: %s: %s", step.ConfigRef.Name, err),
+				)
+
+				// we are watching the source object which should re-trigger the reconcile loop
+				return ctrl.Result{}, nil
+			}
+			if !ready {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.ConfigRefNotReadyReason,
+					fmt.Sprintf("config ref not yet ready: %s", step.ConfigRef.Name),
+				)
+
+				// we are watching the source object which should re-trigger the reconcile loop
+				return ctrl.Result{}, nil
+			}
 		}
-		if !ready {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.ConfigRefNotReadyReason,
-				fmt.Sprintf("config ref not yet ready: %s", obj.Spec.ConfigRef.Name),
-			)
 
-			// we are watching the source object which should re-trigger the reconcile loop
-			return ctrl.Result{}, nil
-		}
-	}
+		if step.PatchStrategicMerge != nil {
+			ready, err := r.checkSourceReadiness(ctx, step.PatchStrategicMerge.Source.SourceRef)
+			if err != nil {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.PatchStrategicMergeSourceRefNotReadyWithErrorReason,
+					fmt.Sprintf("patch strategic merge source ref not yet ready with error: %s: %s", step.PatchStrategicMerge.Source.SourceRef.Name, err),
+				)
 
-	if obj.Spec.PatchStrategicMerge != nil {
-		ready, err := r.checkSourceReadiness(ctx, obj.Spec.PatchStrategicMerge.Source.SourceRef)
-		if err != nil {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.PatchStrategicMergeSourceRefNotReadyWithErrorReason,
-				fmt.Sprintf("patch strategic merge source ref not yet ready with error: %s: %s", obj.Spec.PatchStrategicMerge.Source.SourceRef.Name, err),
-			)
+				return ctrl.Result{}, nil
+			}
 
-			return ctrl.Result{}, nil
+			if !ready {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.PatchStrategicMergeSourceRefNotReadyReason,
+					fmt.Sprintf("patch strategic merge source ref not yet ready: %s", step.PatchStrategicMerge.Source.SourceRef.Name),
+				)
+
+				return ctrl.Result{}, nil
+			}
 		}
 
-		if !ready {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.PatchStrategicMergeSourceRefNotReadyReason,
-				fmt.Sprintf("patch strategic merge source ref not yet ready: %s", obj.Spec.PatchStrategicMerge.Source.SourceRef.Name),
-			)
+		if step.Kustomize != nil && step.Kustomize.SourceRef != nil {
+			ready, err := r.checkSourceReadiness(ctx, *step.Kustomize.SourceRef)
+			if err != nil {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.KustomizeSourceRefNotReadyWithErrorReason,
+					fmt.Sprintf("kustomize source ref not yet ready with error: %s: %s", step.Kustomize.SourceRef.Name, err),
+				)
 
-			return ctrl.Result{}, nil
+				return ctrl.Result{}, nil
+			}
+
+			if !ready {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.KustomizeSourceRefNotReadyReason,
+					fmt.Sprintf("kustomize source ref not yet ready: %s", step.Kustomize.SourceRef.Name),
+				)
+
+				return ctrl.Result{}, nil
+			}
 		}
 	}
 
@@ -306,6 +376,9 @@ func (r *ConfigurationReconciler) reconcile(
 		)
 	}
 
+	// the violations of the schema quote the values, which may be sensitive
+	ctx = redact.NewContext(ctx, redact.New())
+
 	size, err := r.MutationReconciler.ReconcileMutationObject(ctx, obj)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -319,6 +392,33 @@ func (r *ConfigurationReconciler) reconcile(
 			return ctrl.Result{}, err
 		}
 
+		if errors.Is(err, snapshot.ErrEncryption) {
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.SnapshotEncryptionFailedReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
+		// a value which doesn't match the schema fails every time until the values or the schema change
+		var validationErr *values.ValidationError
+		if errors.As(err, &validationErr) {
+			obj.Status.SchemaViolations = schemaViolations(ctx, validationErr)
+			status.MarkAsStalled(r.EventRecorder, obj, v1alpha1.ValuesInvalidReason, err.Error())
+
+			return ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}, nil
+		}
+
+		if errors.Is(err, configdata.ErrInvalid) {
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ConfigDataInvalidReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
+		if errors.Is(err, errDecryption) {
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.DecryptionFailedReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
 		err = fmt.Errorf("failed to reconcile mutation object: %w", err)
 		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ReconcileMutationObjectFailedReason, err.Error())
 
@@ -377,7 +477,7 @@ func (r *ConfigurationReconciler) findObjects(sourceKey, configKey string) handl
 }
 
 // this function will enqueue a reconciliation.
-func (r *ConfigurationReconciler) findObjectsForGitRepository(keys ...string) handler.MapFunc {
+func (r *ConfigurationReconciler) findObjectsForSource(keys ...string) handler.MapFunc {
 	return func(ctx context.Context, obj client.Object) []reconcile.Request {
 		cfgs := &v1alpha1.ConfigurationList{}
 		for _, key := range keys {
@@ -410,6 +510,14 @@ func (r *ConfigurationReconciler) checkReadiness(
 		if err := r.Get(ctx, obj.GetObjectKey(), ref); err != nil {
 			return false, fmt.Errorf("failed to find component version source: %w", err)
 		}
+	case sourcev1.GitRepositoryKind, sourcev1.BucketKind, sourcev1.OCIRepositoryKind, sourcev1.HelmChartKind:
+		if obj.Namespace == "" {
+			obj.Namespace = ns
+		}
+		ref, _ = newFluxSource(obj.Kind)
+		if err := r.Get(ctx, obj.GetObjectKey(), ref); err != nil {
+			return false, fmt.Errorf("failed to find flux source: %w", err)
+		}
 	default:
 		// if the APIVersion is not set then default to "delivery.ocm.software/v1alpha1"
 		if obj.APIVersion == "" {
@@ -454,8 +562,8 @@ func (r *ConfigurationReconciler) checkSourceReadiness(
 ) (bool, error) {
 	var ref conditions.Getter
 	switch obj.Kind {
-	case sourcev1.GitRepositoryKind:
-		ref = &sourcev1.GitRepository{}
+	case sourcev1.GitRepositoryKind, sourcev1.BucketKind, sourcev1.OCIRepositoryKind, sourcev1.HelmChartKind:
+		ref, _ = newFluxSource(obj.Kind)
 	case v1alpha1.ResourceKind:
 		ref = &v1alpha1.Resource{}
 	case v1alpha1.ConfigurationKind:
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	k8sapierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -858,6 +859,9 @@ func TestConfigurationValuesFrom(t *testing.T) {
 	testCases := []struct {
 		name           string
 		expectedError  error
+		expectedReason string
+		reconcileFails bool
+		hiddenValue    string
 		expectedConfig map[string]string
 		configuration  func(source client.Object) *v1alpha1.Configuration
 		setup          func() client.Object
@@ -894,6 +898,81 @@ func TestConfigurationValuesFrom(t *testing.T) {
 				return gitRepo
 			},
 		},
+		{
+			name:           "configuration values from a list of value sources",
+			expectedConfig: commonExpectedData,
+			configuration: func(source client.Object) *v1alpha1.Configuration {
+				configuration := DefaultConfiguration.DeepCopy()
+				configuration.Status.SnapshotName = "configuration-snapshot"
+				configuration.Spec.ValuesSources = []v1alpha1.ValuesSource{{
+					FluxSource: &v1alpha1.FluxValuesSource{
+						SourceRef: meta.NamespacedObjectKindReference{
+							Kind:      "GitRepository",
+							Name:      source.GetName(),
+							Namespace: source.GetNamespace(),
+						},
+						Path:    "config/values.yaml",
+						SubPath: "test.backend",
+					},
+				}}
+				configuration.Spec.Values = nil
+
+				return configuration
+			},
+			setup: func() client.Object {
+				path := "/file.tar.gz"
+				server := ghttp.NewServer()
+				server.RouteToHandler("GET", path, func(writer http.ResponseWriter, request *http.Request) {
+					http.ServeFile(writer, request, "testdata/git-repo.tar.gz")
+				})
+				checksum := "87670827f3d1a10094e3226381c95168b6ce92344ac1a1c2345caaeb7cc6b7d8"
+				gitRepo := createGitRepository("patch-repo", "default", server.URL()+path, checksum)
+				return gitRepo
+			},
+		},
+		{
+			name:           "configuration values from Bucket",
+			expectedConfig: commonExpectedData,
+			configuration: func(source client.Object) *v1alpha1.Configuration {
+				configuration := DefaultConfiguration.DeepCopy()
+				configuration.Status.SnapshotName = "configuration-snapshot"
+				configuration.Spec.ValuesSources = []v1alpha1.ValuesSource{{
+					FluxSource: &v1alpha1.FluxValuesSource{
+						SourceRef: meta.NamespacedObjectKindReference{
+							Kind:      sourcev1.BucketKind,
+							Name:      source.GetName(),
+							Namespace: source.GetNamespace(),
+						},
+						Path:    "config/values.yaml",
+						SubPath: "test.backend",
+					},
+				}}
+				configuration.Spec.Values = nil
+
+				return configuration
+			},
+			setup: func() client.Object {
+				path := "/file.tar.gz"
+				server := ghttp.NewServer()
+				server.RouteToHandler("GET", path, func(writer http.ResponseWriter, request *http.Request) {
+					http.ServeFile(writer, request, "testdata/git-repo.tar.gz")
+				})
+
+				return &sourcev1.Bucket{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "values-bucket",
+						Namespace: "default",
+					},
+					Status: sourcev1.BucketStatus{
+						Artifact: &meta.Artifact{
+							URL:      server.URL() + path,
+							Revision: "sha256:87670827f3d1a10094e3226381c95168b6ce92344ac1a1c2345caaeb7cc6b7d8",
+							Digest:   "87670827f3d1a10094e3226381c95168b6ce92344ac1a1c2345caaeb7cc6b7d8",
+						},
+					},
+				}
+			},
+		},
 		{
 			name:           "configuration values from ConfigMap",
 			expectedConfig: commonExpectedData,
@@ -929,6 +1008,137 @@ func TestConfigurationValuesFrom(t *testing.T) {
 				return configMap
 			},
 		},
+		{
+			name:           "configuration values layered from ConfigMap and inline values",
+			expectedConfig: map[string]string{"PODINFO_UI_MESSAGE": "this is a new message", "PODINFO_UI_COLOR": "teal"},
+			configuration: func(source client.Object) *v1alpha1.Configuration {
+				configuration := DefaultConfiguration.DeepCopy()
+				configuration.Status.SnapshotName = "configuration-snapshot"
+				configuration.Spec.ValuesSources = []v1alpha1.ValuesSource{{
+					ConfigMapSource: &v1alpha1.ConfigMapSource{
+						SourceRef: meta.LocalObjectReference{
+							Name: "test-config-data",
+						},
+						Key:     "values.yaml",
+						SubPath: "test.backend",
+					},
+				}}
+				configuration.Spec.Values = &apiextensionsv1.JSON{Raw: []byte(`{"color":"teal"}`)}
+
+				return configuration
+			},
+			setup: func() client.Object {
+				valuesFile, err := os.ReadFile("testdata/values.yaml")
+				require.NoError(t, err)
+				configMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config-data",
+						Namespace: "default",
+					},
+					Data: map[string]string{
+						"values.yaml": string(valuesFile),
+					},
+				}
+
+				return configMap
+			},
+		},
+		{
+			name:           "configuration values from Secret",
+			expectedConfig: commonExpectedData,
+			configuration: func(source client.Object) *v1alpha1.Configuration {
+				configuration := DefaultConfiguration.DeepCopy()
+				configuration.Status.SnapshotName = "configuration-snapshot"
+				configuration.Spec.ValuesSources = []v1alpha1.ValuesSource{{
+					SecretSource: &v1alpha1.SecretSource{
+						SourceRef: meta.LocalObjectReference{
+							Name: "test-secret-data",
+						},
+						Key:     "values.yaml",
+						SubPath: "test.backend",
+					},
+				}}
+				configuration.Spec.Values = nil
+
+				return configuration
+			},
+			setup: func() client.Object {
+				valuesFile, err := os.ReadFile("testdata/values.yaml")
+				require.NoError(t, err)
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-secret-data",
+						Namespace: "default",
+					},
+					Data: map[string][]byte{
+						"values.yaml": valuesFile,
+					},
+				}
+
+				return secret
+			},
+		},
+		{
+			name: "configuration values from malformed Secret",
+			expectedError: &k8sapierr.StatusError{
+				ErrStatus: metav1.Status{
+					Reason: metav1.StatusReasonNotFound,
+				},
+			},
+			expectedReason: v1alpha1.ReconcileMutationObjectFailedReason,
+			reconcileFails: true,
+			hiddenValue:    "s3cret-token",
+			configuration: func(source client.Object) *v1alpha1.Configuration {
+				configuration := DefaultConfiguration.DeepCopy()
+				configuration.Status.SnapshotName = "configuration-snapshot"
+				configuration.Spec.ValuesSources = []v1alpha1.ValuesSource{{
+					SecretSource: &v1alpha1.SecretSource{
+						SourceRef: meta.LocalObjectReference{
+							Name: source.GetName(),
+						},
+						Key: "values.yaml",
+					},
+				}}
+				configuration.Spec.Values = nil
+
+				return configuration
+			},
+			setup: func() client.Object {
+				return &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-secret-data-malformed",
+						Namespace: "default",
+					},
+					Data: map[string][]byte{
+						// the parser quotes the value it can't decode
+						"values.yaml": []byte("password: !!int s3cret-token\n"),
+					},
+				}
+			},
+		},
+		{
+			name:           "configuration values from optional missing Secret",
+			expectedConfig: map[string]string{"PODINFO_UI_MESSAGE": "Hello, world!", "PODINFO_UI_COLOR": "red"},
+			configuration: func(client.Object) *v1alpha1.Configuration {
+				configuration := DefaultConfiguration.DeepCopy()
+				configuration.Status.SnapshotName = "configuration-snapshot"
+				configuration.Spec.ValuesSources = []v1alpha1.ValuesSource{{
+					SecretSource: &v1alpha1.SecretSource{
+						SourceRef: meta.LocalObjectReference{
+							Name: "test-secret-data-does-not-exist",
+						},
+						Key:      "values.yaml",
+						Optional: true,
+					},
+				}}
+				configuration.Spec.Values = nil
+
+				return configuration
+			},
+			setup: func() client.Object {
+				return nil
+			},
+		},
 		{
 			name:           "configuration values from optional missing ConfigMap",
 			expectedConfig: map[string]string{"PODINFO_UI_MESSAGE": "Hello, world!", "PODINFO_UI_COLOR": "red"},
@@ -953,6 +1163,47 @@ func TestConfigurationValuesFrom(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "configuration values from encrypted ConfigMap without decryption secret",
+			expectedError: &k8sapierr.StatusError{
+				ErrStatus: metav1.Status{
+					Reason: metav1.StatusReasonNotFound,
+				},
+			},
+			expectedReason: v1alpha1.DecryptionFailedReason,
+			reconcileFails: true,
+			configuration: func(source client.Object) *v1alpha1.Configuration {
+				configuration := DefaultConfiguration.DeepCopy()
+				configuration.Status.SnapshotName = "configuration-snapshot"
+				configuration.Spec.ValuesSources = []v1alpha1.ValuesSource{{
+					ConfigMapSource: &v1alpha1.ConfigMapSource{
+						SourceRef: meta.LocalObjectReference{
+							Name: source.GetName(),
+						},
+						Key: "values.yaml",
+					},
+				}}
+				configuration.Spec.Values = nil
+
+				return configuration
+			},
+			setup: func() client.Object {
+				return &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-config-data-encrypted",
+						Namespace: "default",
+					},
+					Data: map[string]string{
+						"values.yaml": `message: ENC[AES256_GCM,data:AAAA,iv:AAAA,tag:AAAA,type:str]
+sops:
+  lastmodified: "2024-01-01T00:00:00Z"
+  mac: ENC[AES256_GCM,data:AAAA,iv:AAAA,tag:AAAA,type:str]
+  version: 3.9.0
+`,
+					},
+				}
+			},
+		},
 		{
 			name: "configuration values from missing ConfigMap",
 			expectedError: &k8sapierr.StatusError{
@@ -1064,7 +1315,11 @@ func TestConfigurationValuesFrom(t *testing.T) {
 					Name:      configuration.Name,
 				},
 			})
-			require.NoError(t, err)
+			if tc.reconcileFails {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
 
 			getErr := client.Get(context.Background(), types.NamespacedName{
 				Namespace: configuration.Namespace,
@@ -1112,6 +1367,15 @@ func TestConfigurationValuesFrom(t *testing.T) {
 				assert.Equal(t, k8sapierr.ReasonForError(tc.expectedError), k8sapierr.ReasonForError(err))
 			}
 
+			if tc.expectedReason != "" {
+				assert.Equal(t, tc.expectedReason, conditions.GetReason(configuration, meta.ReadyCondition))
+			}
+
+			if tc.hiddenValue != "" {
+				message := conditions.GetMessage(configuration, meta.ReadyCondition)
+				assert.Contains(t, message, "invalid YAML")
+				assert.NotContains(t, message, tc.hiddenValue)
+			}
 		})
 	}
 }
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"ocm.software/ocm/api/ocm/ocmutils/localize"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+	"github.com/open-component-model/ocm-controller/pkg/engine"
+	"github.com/open-component-model/ocm-controller/pkg/redact"
+	"github.com/open-component-model/ocm-controller/pkg/values"
+)
+
+// evaluateSubstitutions evaluates the values of the substitutions with the engine of the configuration.
+func (m *MutationReconcileLooper) evaluateSubstitutions(
+	spec configdata.ConfigurationSpec,
+	subst localize.Substitutions,
+	configValues []byte,
+) (localize.Substitutions, error) {
+	jsonSchema, cueSchema, err := configurationSchema(spec.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if cueSchema != "" && spec.Engine != configdata.EngineCUE {
+		return nil, errors.New("schemas written in CUE require the cue engine")
+	}
+
+	userValues := map[string]any{}
+	if err := json.Unmarshal(configValues, &userValues); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal values: %w", err)
+	}
+
+	mergedValues, err := values.Merge(values.Layer{Values: spec.Defaults}, values.Layer{Values: userValues})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge defaults and values: %w", err)
+	}
+
+	// the values the rules are evaluated with are validated, so a default can't violate the schema either
+	if len(jsonSchema) > 0 {
+		if err := values.Validate(jsonSchema, mergedValues); err != nil {
+			return nil, fmt.Errorf("validation failed: %w", err)
+		}
+	}
+
+	if spec.Engine == "" || spec.Engine == configdata.EngineSpiff {
+		defaults, err := json.Marshal(spec.Defaults)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal configuration defaults: %w", err)
+		}
+
+		return m.generateSubstitutions(subst, defaults, configValues)
+	}
+
+	var evaluator engine.Evaluator
+	switch spec.Engine {
+	case configdata.EngineCUE:
+		if evaluator, err = engine.NewCUE(mergedValues, cueSchema); err != nil {
+			return nil, err
+		}
+	case configdata.EngineGoTemplate:
+		evaluator = engine.NewGoTemplate(mergedValues)
+	default:
+		return nil, fmt.Errorf("unknown configuration engine %s", spec.Engine)
+	}
+
+	result := make(localize.Substitutions, 0, len(subst))
+	for _, s := range subst {
+		value, err := evaluator.Evaluate(s.ValueMapping.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate %s: %w", s.ValueMapping.Name, err)
+		}
+
+		s.ValueMapping.Value = value
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+// configurationSchema returns the schema of a configuration, which is either a JSON schema or a string
+// holding a CUE schema.
+func configurationSchema(schema json.RawMessage) ([]byte, string, error) {
+	schema = bytes.TrimSpace(schema)
+	if len(schema) == 0 || bytes.Equal(schema, []byte("null")) {
+		return nil, "", nil
+	}
+
+	if schema[0] != '"' {
+		return schema, "", nil
+	}
+
+	var cueSchema string
+	if err := json.Unmarshal(schema, &cueSchema); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal configuration schema: %w", err)
+	}
+
+	return nil, cueSchema, nil
+}
+
+// schemaViolations returns the violations of the validation error for the status of the configuration.
+// The messages quote the offending values, sensitive values are masked with the redactor of the context.
+func schemaViolations(ctx context.Context, err *values.ValidationError) []v1alpha1.SchemaViolation {
+	redactor := redact.FromContext(ctx)
+
+	violations := make([]v1alpha1.SchemaViolation, 0, len(err.Violations))
+	for _, v := range err.Violations {
+		violations = append(violations, v1alpha1.SchemaViolation{Pointer: v.Pointer, Message: redactor.String(v.Message)})
+	}
+
+	return violations
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ocm.software/ocm/api/ocm/ocmutils/localize"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+	"github.com/open-component-model/ocm-controller/pkg/redact"
+	"github.com/open-component-model/ocm-controller/pkg/values"
+)
+
+func TestEvaluateSubstitutions(t *testing.T) {
+	substitution := func(value string) localize.Substitutions {
+		return localize.Substitutions{
+			localize.Substitution{
+				FilePath: "deployment.yaml",
+				ValueMapping: localize.ValueMapping{
+					Name:      "subst-0",
+					ValuePath: "spec.replicas",
+					Value:     json.RawMessage(value),
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		spec     configdata.ConfigurationSpec
+		value    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "spiff",
+			spec:     configdata.ConfigurationSpec{Defaults: map[string]any{"replicas": 1}},
+			value:    `"(( replicas ))"`,
+			expected: `3`,
+		},
+		{
+			name: "cue",
+			spec: configdata.ConfigurationSpec{
+				Engine:   configdata.EngineCUE,
+				Defaults: map[string]any{"replicas": 1},
+				Schema:   json.RawMessage(`"replicas: int & <10"`),
+			},
+			value:    `"replicas * 2"`,
+			expected: `6`,
+		},
+		{
+			name: "cue validation",
+			spec: configdata.ConfigurationSpec{
+				Engine: configdata.EngineCUE,
+				Schema: json.RawMessage(`"replicas: int & <3"`),
+			},
+			value: `"replicas"`,
+			err:   "replicas: invalid value 3 (out of bound <3)",
+		},
+		{
+			name:     "go template",
+			spec:     configdata.ConfigurationSpec{Engine: configdata.EngineGoTemplate},
+			value:    `"{{ .replicas }}"`,
+			expected: `3`,
+		},
+		{
+			name: "cue schema without cue engine",
+			spec: configdata.ConfigurationSpec{
+				Engine: configdata.EngineGoTemplate,
+				Schema: json.RawMessage(`"replicas: int"`),
+			},
+			value: `"{{ .replicas }}"`,
+			err:   "schemas written in CUE require the cue engine",
+		},
+		{
+			name: "json schema",
+			spec: configdata.ConfigurationSpec{
+				Defaults: map[string]any{"name": 1},
+				Schema: json.RawMessage(`{
+  "$defs": {"replicas": {"type": "integer", "maximum": 2}},
+  "properties": {"name": {"type": "string"}, "replicas": {"$ref": "#/$defs/replicas"}}
+}`),
+			},
+			value: `"(( replicas ))"`,
+			err:   "values don't match the schema: /name: got number, want string; /replicas: maximum: got 3, want 2",
+		},
+		{
+			name:  "unknown engine",
+			spec:  configdata.ConfigurationSpec{Engine: "jsonnet"},
+			value: `"replicas"`,
+			err:   "unknown configuration engine jsonnet",
+		},
+	}
+
+	m := &MutationReconcileLooper{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := m.evaluateSubstitutions(tc.spec, substitution(tc.value), []byte(`{"replicas": 3}`))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, result, 1)
+			assert.JSONEq(t, tc.expected, string(result[0].ValueMapping.Value))
+			assert.Equal(t, "spec.replicas", result[0].ValueMapping.ValuePath)
+		})
+	}
+}
+
+func TestSchemaViolations(t *testing.T) {
+	m := &MutationReconcileLooper{}
+	spec := configdata.ConfigurationSpec{
+		Engine: configdata.EngineGoTemplate,
+		Schema: json.RawMessage(`{"required": ["name"], "properties": {"replicas": {"type": "string"}}}`),
+	}
+
+	_, err := m.evaluateSubstitutions(spec, nil, []byte(`{"replicas": 3}`))
+
+	var validationErr *values.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []v1alpha1.SchemaViolation{
+		{Pointer: "", Message: "missing property 'name'"},
+		{Pointer: "/replicas", Message: "got number, want string"},
+	}, schemaViolations(context.Background(), validationErr))
+}
+
+func TestSchemaViolationsRedacted(t *testing.T) {
+	m := &MutationReconcileLooper{}
+	spec := configdata.ConfigurationSpec{
+		Engine: configdata.EngineGoTemplate,
+		Schema: json.RawMessage(`{"properties": {"token": {"type": "string", "pattern": "^[a-z]+$"}}}`),
+	}
+
+	// values read from a secret are added to the redactor of the reconciliation
+	redactor := redact.New()
+	redactor.Add(map[string]any{"token": "s3cret-token"})
+	ctx := redact.NewContext(context.Background(), redactor)
+
+	_, err := m.evaluateSubstitutions(spec, nil, []byte(`{"token": "s3cret-token"}`))
+
+	var validationErr *values.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	violations := schemaViolations(ctx, validationErr)
+	assert.Equal(t, []v1alpha1.SchemaViolation{
+		{Pointer: "/token", Message: "'***' does not match pattern '^[a-z]+$'"},
+	}, violations)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/helm"
+	"github.com/open-component-model/ocm-controller/pkg/untar"
+	"github.com/open-component-model/ocm-controller/pkg/values"
+)
+
+// mutateHelmTemplate renders the chart of the resource with the values of the step and returns a directory
+// holding the rendered manifests.
+func (m *MutationReconcileLooper) mutateHelmTemplate(
+	ctx context.Context,
+	obj v1alpha1.MutationObject,
+	mutationSpec *v1alpha1.MutationSpec,
+	spec *v1alpha1.HelmTemplate,
+	sourceData []byte,
+) (string, ocmmetav1.Identity, error) {
+	if !isTar(sourceData) {
+		return "", ocmmetav1.Identity{}, errTar
+	}
+
+	chartValues := map[string]any{}
+	if spec.Values != nil || len(spec.ValuesSources) > 0 {
+		valuesSpec := &v1alpha1.MutationSpec{
+			Values:        spec.Values,
+			ValuesSources: spec.ValuesSources,
+			Decryption:    mutationSpec.Decryption,
+		}
+
+		var err error
+		if chartValues, err = m.getValues(ctx, valuesSpec, obj.GetNamespace(), obj.GetName()); err != nil {
+			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to get chart values: %w", err)
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "helm-template-")
+	if err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("tmp dir error: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := untar.Untar(bytes.NewReader(sourceData), tmpDir); err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to untar resource: %w", err)
+	}
+
+	chartDir, err := helm.FindChart(tmpDir)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	namespace := spec.Namespace
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+
+	manifests, err := helm.Template(ctx, chartDir, helm.Options{
+		ReleaseName:  spec.ReleaseName,
+		Namespace:    namespace,
+		Values:       chartValues,
+		IncludeCRDs:  spec.IncludeCRDs,
+		DisableHooks: spec.DisableHooks,
+		KubeVersion:  spec.KubeVersion,
+		APIVersions:  spec.APIVersions,
+	})
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	outputDir, err := writeKustomizeOutput(spec.OutputPath, manifests)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	digest, err := specDigest(spec)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, errors.Join(err, os.RemoveAll(outputDir))
+	}
+
+	valuesDigest, err := values.Digest(chartValues)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, errors.Join(fmt.Errorf("failed to compute digest of values: %w", err), os.RemoveAll(outputDir))
+	}
+
+	return outputDir, ocmmetav1.Identity{
+		v1alpha1.HelmTemplateDigestKey: digest,
+		v1alpha1.ValuesDigestKey:       valuesDigest,
+		v1alpha1.MutationObjectUUIDKey: string(obj.GetUID()),
+	}, nil
+}
+
+// renderedBy returns the renderer of the steps rendering the resource into manifests, if any.
+func renderedBy(steps []v1alpha1.MutationStep) string {
+	for _, step := range steps {
+		if step.HelmTemplate != nil {
+			return v1alpha1.HelmTemplateRenderer
+		}
+	}
+
+	return ""
+}
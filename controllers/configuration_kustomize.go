@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	generator "github.com/fluxcd/pkg/kustomize"
+	"sigs.k8s.io/kustomize/api/konfig"
+	kustypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+
+	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/untar"
+)
+
+// defaultKustomizeOutputPath is the file the built manifests are written to if no output path is set.
+const defaultKustomizeOutputPath = "manifests.yaml"
+
+// mutateKustomize builds a kustomization against the resource tree with the files of the kustomize source
+// and returns a directory holding the built manifests.
+func (m *MutationReconcileLooper) mutateKustomize(
+	ctx context.Context,
+	obj v1alpha1.MutationObject,
+	spec *v1alpha1.Kustomize,
+	sourceData []byte,
+) (string, ocmmetav1.Identity, error) {
+	if !isTar(sourceData) {
+		return "", ocmmetav1.Identity{}, errTar
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kustomization-")
+	if err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("tmp dir error: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	workDir, err := securejoin.SecureJoin(tmpDir, "work")
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	identity := ocmmetav1.Identity{}
+	if spec.SourceRef != nil {
+		if identity, err = m.fetchPatchSource(ctx, obj, *spec.SourceRef, workDir); err != nil {
+			return "", ocmmetav1.Identity{}, err
+		}
+	}
+
+	const perm = 0o755
+	if err := os.MkdirAll(workDir, perm); err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to create work dir: %w", err)
+	}
+
+	if err := untar.Untar(bytes.NewReader(sourceData), workDir); err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to untar resource: %w", err)
+	}
+
+	kustomizationDir, err := securejoin.SecureJoin(workDir, spec.Path)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	if spec.Kustomization != nil {
+		if err := writeKustomization(kustomizationDir, spec.Kustomization.Raw); err != nil {
+			return "", ocmmetav1.Identity{}, err
+		}
+	}
+
+	result, err := generator.SecureBuild(workDir, kustomizationDir, false)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to build kustomization: %w", err)
+	}
+
+	manifests, err := result.AsYaml()
+	if err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to marshal kustomization output: %w", err)
+	}
+
+	outputDir, err := writeKustomizeOutput(spec.OutputPath, manifests)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	digest, err := specDigest(spec)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, errors.Join(err, os.RemoveAll(outputDir))
+	}
+
+	identity[v1alpha1.KustomizationDigestKey] = digest
+	identity[v1alpha1.MutationObjectUUIDKey] = string(obj.GetUID())
+
+	return outputDir, identity, nil
+}
+
+// writeKustomization writes an inline kustomization to dir. It replaces any kustomization file in dir,
+// because kustomize refuses to build a directory with more than one.
+func writeKustomization(dir string, raw []byte) error {
+	kus := kustypes.Kustomization{}
+	if err := yaml.UnmarshalStrict(raw, &kus); err != nil {
+		return fmt.Errorf("invalid kustomization: %w", err)
+	}
+
+	if kus.APIVersion == "" {
+		kus.APIVersion = kustypes.KustomizationVersion
+	}
+
+	if kus.Kind == "" {
+		kus.Kind = kustypes.KustomizationKind
+	}
+
+	manifest, err := yaml.Marshal(kus)
+	if err != nil {
+		return fmt.Errorf("failed to marshal kustomization: %w", err)
+	}
+
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove kustomization file: %w", err)
+		}
+	}
+
+	const perm = 0o755
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return fmt.Errorf("failed to create kustomization dir: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, konfig.DefaultKustomizationFileName()), manifest, FSOwnerReadWrite)
+}
+
+// writeKustomizeOutput writes the built manifests to outputPath in a new directory.
+func writeKustomizeOutput(outputPath string, manifests []byte) (_ string, err error) {
+	if outputPath == "" {
+		outputPath = defaultKustomizeOutputPath
+	}
+
+	outputDir, err := os.MkdirTemp("", "kustomize-output-")
+	if err != nil {
+		return "", fmt.Errorf("tmp dir error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, os.RemoveAll(outputDir))
+		}
+	}()
+
+	path, err := securejoin.SecureJoin(outputDir, outputPath)
+	if err != nil {
+		return "", err
+	}
+
+	const perm = 0o755
+	if err := os.MkdirAll(filepath.Dir(path), perm); err != nil {
+		return "", fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, manifests, FSOwnerReadWrite); err != nil {
+		return "", fmt.Errorf("failed to write kustomization output: %w", err)
+	}
+
+	return outputDir, nil
+}
+
+// specDigest identifies the spec of a mutation step. Content referenced by the spec, like the artifact of
+// a source, isn't covered and needs to be identified separately.
+func specDigest(spec any) (string, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal step spec: %w", err)
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return hex.EncodeToString(sum[:]), nil
+}
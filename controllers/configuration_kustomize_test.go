@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/snapshot"
+)
+
+const kustomizeTestDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: podinfo
+        image: ghcr.io/stefanprodan/podinfo:6.0.0
+`
+
+func TestMutateKustomize(t *testing.T) {
+	resourceDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(resourceDir, "manifests"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(resourceDir, "manifests", "deployment.yaml"), []byte(kustomizeTestDeployment), 0o600))
+
+	sourceData, err := snapshot.TarDirectory(resourceDir)
+	require.NoError(t, err)
+
+	kustomization := `{
+  "resources": ["deployment.yaml"],
+  "namePrefix": "eu-",
+  "namespace": "production",
+  "images": [{"name": "ghcr.io/stefanprodan/podinfo", "newTag": "6.1.0"}],
+  "patches": [{
+    "target": {"kind": "Deployment", "name": "podinfo"},
+    "patch": "- op: replace\n  path: /spec/replicas\n  value: 3"
+  }],
+  "configMapGenerator": [{"name": "settings", "literals": ["region=eu-west-1"]}]
+}`
+
+	obj := &v1alpha1.Configuration{ObjectMeta: metav1.ObjectMeta{Name: "test", UID: "uid"}}
+	spec := &v1alpha1.Kustomize{
+		Kustomization: &apiextensionsv1.JSON{Raw: []byte(kustomization)},
+		Path:          "manifests",
+	}
+
+	m := &MutationReconcileLooper{}
+	outputDir, identity, err := m.mutateKustomize(context.Background(), obj, spec, sourceData)
+	require.NoError(t, err)
+	defer os.RemoveAll(outputDir)
+
+	manifests, err := os.ReadFile(filepath.Join(outputDir, defaultKustomizeOutputPath))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(manifests), "name: eu-podinfo")
+	assert.Contains(t, string(manifests), "namespace: production")
+	assert.Contains(t, string(manifests), "image: ghcr.io/stefanprodan/podinfo:6.1.0")
+	assert.Contains(t, string(manifests), "replicas: 3")
+	assert.Contains(t, string(manifests), "region: eu-west-1")
+	assert.Equal(t, "uid", identity[v1alpha1.MutationObjectUUIDKey])
+	assert.NotEmpty(t, identity[v1alpha1.KustomizationDigestKey])
+
+	spec.Kustomization = &apiextensionsv1.JSON{Raw: []byte(`{"resources": ["deployment.yaml"], "unknown": true}`)}
+	_, _, err = m.mutateKustomize(context.Background(), obj, spec, sourceData)
+	assert.ErrorContains(t, err, "invalid kustomization")
+}
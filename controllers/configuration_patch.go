@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/substitute"
+	"github.com/open-component-model/ocm-controller/pkg/untar"
+)
+
+// mutateJSONPatch applies the operations of a JSON patch step to the resource.
+func (m *MutationReconcileLooper) mutateJSONPatch(
+	obj v1alpha1.MutationObject,
+	name string,
+	spec *v1alpha1.JSONPatch,
+	sourceData []byte,
+) (string, ocmmetav1.Identity, error) {
+	operations, err := json.Marshal(spec.Operations)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to marshal operations: %w", err)
+	}
+
+	return m.applyPatch(obj, spec, substitute.Patch{
+		Name:       name,
+		Target:     patchTarget(spec.Target),
+		Operations: operations,
+	}, sourceData)
+}
+
+// mutateYQ applies the expression of a yq step to the resource.
+func (m *MutationReconcileLooper) mutateYQ(
+	obj v1alpha1.MutationObject,
+	name string,
+	spec *v1alpha1.YQExpression,
+	sourceData []byte,
+) (string, ocmmetav1.Identity, error) {
+	return m.applyPatch(obj, spec, substitute.Patch{
+		Name:       name,
+		Target:     patchTarget(spec.Target),
+		Expression: spec.Expression,
+	}, sourceData)
+}
+
+// applyPatch extracts the resource, applies the patch and records the patched files. The identity of the
+// result is derived from the spec of the step.
+func (m *MutationReconcileLooper) applyPatch(
+	obj v1alpha1.MutationObject,
+	spec any,
+	patch substitute.Patch,
+	sourceData []byte,
+) (_ string, _ ocmmetav1.Identity, err error) {
+	if !isTar(sourceData) {
+		return "", ocmmetav1.Identity{}, errTar
+	}
+
+	digest, err := specDigest(spec)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	sourceDir, err := os.MkdirTemp("", "patch-")
+	if err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("tmp dir error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			os.RemoveAll(sourceDir)
+		}
+	}()
+
+	if err := untar.Untar(bytes.NewReader(sourceData), sourceDir); err != nil {
+		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to untar resource: %w", err)
+	}
+
+	files, err := substitute.ApplyPatch(sourceDir, patch)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	obj.GetStatus().MatchedFiles = append(obj.GetStatus().MatchedFiles, v1alpha1.RuleMatch{
+		Rule:    patch.Name,
+		Pattern: patch.Target.File,
+		Files:   files,
+	})
+
+	return sourceDir, ocmmetav1.Identity{
+		v1alpha1.PatchDigestKey:        digest,
+		v1alpha1.MutationObjectUUIDKey: string(obj.GetUID()),
+	}, nil
+}
+
+func patchTarget(target v1alpha1.PatchTarget) substitute.Target {
+	if target.Document == nil {
+		return substitute.Target{File: target.File}
+	}
+
+	return substitute.DocumentTarget(target.File, target.Document.Kind, target.Document.Name)
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+	"github.com/open-component-model/ocm-controller/pkg/values"
+)
+
+// configProfile is the profile of the config data selected by a configuration. It records the profile
+// which was applied.
+type configProfile struct {
+	name string
+	// fromNamespace is set if the profile is selected by the label of the namespace. Config data without
+	// the profile is applied without a profile then, because the label applies to every configuration.
+	fromNamespace bool
+	applied       string
+}
+
+// namedRule is a configuration rule with its position in the config data.
+type namedRule struct {
+	name string
+	configdata.ConfigRule
+}
+
+// selectProfile returns the profile set in the spec of the configuration or by the label of its namespace.
+// Localizations don't apply profiles.
+func (m *MutationReconcileLooper) selectProfile(ctx context.Context, obj v1alpha1.MutationObject) (*configProfile, error) {
+	cfg, ok := obj.(*v1alpha1.Configuration)
+	if !ok {
+		return &configProfile{}, nil
+	}
+
+	if name := cfg.Spec.Profile; name != "" {
+		return &configProfile{name: name}, nil
+	}
+
+	// only the metadata of namespaces is watched
+	namespace := &metav1.PartialObjectMetadata{}
+	namespace.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
+	if err := m.Client.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &configProfile{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	return &configProfile{name: namespace.GetLabels()[v1alpha1.ConfigProfileLabel], fromNamespace: true}, nil
+}
+
+// findObjectsForNamespace enqueues the configurations of the namespace which select their profile by its label.
+func (r *ConfigurationReconciler) findObjectsForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	cfgs := &v1alpha1.ConfigurationList{}
+	if err := r.List(ctx, cfgs, client.InNamespace(obj.GetName())); err != nil {
+		return []reconcile.Request{}
+	}
+
+	cfgs.Items = slices.DeleteFunc(cfgs.Items, func(cfg v1alpha1.Configuration) bool {
+		return cfg.Spec.Profile != ""
+	})
+
+	return makeRequestsForConfigurations(cfgs.Items...)
+}
+
+// apply layers the selected profile over the configuration. The defaults and helm values of the profile are
+// deep merged over the ones of the configuration, its rules are applied after the rules of the configuration.
+func (p *configProfile) apply(spec configdata.ConfigurationSpec) (configdata.ConfigurationSpec, []namedRule, error) {
+	rules := make([]namedRule, 0, len(spec.Rules))
+	for i, rule := range spec.Rules {
+		rules = append(rules, namedRule{name: fmt.Sprintf("configuration.rules[%d]", i), ConfigRule: rule})
+	}
+
+	if p == nil || p.name == "" {
+		return spec, rules, nil
+	}
+
+	index := slices.IndexFunc(spec.Profiles, func(profile configdata.Profile) bool {
+		return profile.Name == p.name
+	})
+	if index < 0 {
+		if p.fromNamespace {
+			return spec, rules, nil
+		}
+
+		return configdata.ConfigurationSpec{}, nil, fmt.Errorf("profile %s not found in config data", p.name)
+	}
+
+	profile := spec.Profiles[index]
+
+	defaults, err := values.Merge(values.Layer{Values: spec.Defaults}, values.Layer{Values: profile.Defaults})
+	if err != nil {
+		return configdata.ConfigurationSpec{}, nil, fmt.Errorf("failed to merge defaults of profile %s: %w", p.name, err)
+	}
+
+	spec.Defaults = defaults
+
+	if profile.HelmValues != nil {
+		helmValues, err := values.Merge(values.Layer{Values: spec.HelmValues}, values.Layer{Values: profile.HelmValues})
+		if err != nil {
+			return configdata.ConfigurationSpec{}, nil, fmt.Errorf("failed to merge helm values of profile %s: %w", p.name, err)
+		}
+
+		spec.HelmValues = helmValues
+	}
+
+	for i, rule := range profile.Rules {
+		rules = append(rules, namedRule{name: fmt.Sprintf("configuration.profiles[%d].rules[%d]", index, i), ConfigRule: rule})
+	}
+
+	p.applied = p.name
+
+	return spec, rules, nil
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"slices"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fluxSourceKinds are the Flux sources providing an archive artifact which can be used for values,
+// patches and config data.
+var fluxSourceKinds = []string{
+	sourcev1.GitRepositoryKind,
+	sourcev1.BucketKind,
+	sourcev1.OCIRepositoryKind,
+	sourcev1.HelmChartKind,
+}
+
+// fluxSource is a Flux source object with an artifact and conditions.
+type fluxSource interface {
+	client.Object
+	sourcev1.Source
+	conditions.Getter
+}
+
+func isFluxSource(kind string) bool {
+	return slices.Contains(fluxSourceKinds, kind)
+}
+
+// newFluxSource returns an empty object of the given Flux source kind.
+func newFluxSource(kind string) (fluxSource, bool) {
+	switch kind {
+	case sourcev1.GitRepositoryKind:
+		return &sourcev1.GitRepository{}, true
+	case sourcev1.BucketKind:
+		return &sourcev1.Bucket{}, true
+	case sourcev1.OCIRepositoryKind:
+		return &sourcev1.OCIRepository{}, true
+	case sourcev1.HelmChartKind:
+		return &sourcev1.HelmChart{}, true
+	default:
+		return nil, false
+	}
+}
+
+// fluxSourceObjects returns an empty object of every supported Flux source kind, used to set up watches.
+func fluxSourceObjects() []client.Object {
+	objects := make([]client.Object, 0, len(fluxSourceKinds))
+	for _, kind := range fluxSourceKinds {
+		obj, _ := newFluxSource(kind)
+		objects = append(objects, obj)
+	}
+
+	return objects
+}
@@ -155,6 +155,16 @@ func (r *FluxDeployerReconciler) reconcile(
 		return ctrl.Result{RequeueAfter: r.RetryInterval}, nil
 	}
 
+	// flux reads snapshots from the registry as they are, so encrypted content must never reach an OCIRepository
+	if snapshot.Spec.Encryption != nil {
+		err := fmt.Errorf("snapshot %s is encrypted and can't be deployed through an OCIRepository", snapshot.Name)
+		conditions.MarkFalse(obj, meta.ReadyCondition, v1alpha1.EncryptedSnapshotNotSupportedReason, err.Error(), []any{}...)
+		conditions.MarkStalled(obj, v1alpha1.EncryptedSnapshotNotSupportedReason, err.Error(), []any{}...)
+		event.New(r.EventRecorder, obj, nil, eventv1.EventSeverityError, err.Error())
+
+		return ctrl.Result{}, err
+	}
+
 	snapshotRepo, err := ocm.ConstructRepositoryName(snapshot.Spec.Identity)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -193,6 +203,16 @@ func (r *FluxDeployerReconciler) reconcile(
 	}
 
 	if obj.Spec.HelmReleaseTemplate != nil {
+		// snapshots created before artifact metadata was recorded carry no information, so only reject known content
+		if artifact := snapshot.Status.Artifact; artifact != nil && !artifact.IsHelmChart() {
+			err := fmt.Errorf("snapshot %s contains %s content which is not a helm chart", snapshot.Name, artifact.ResourceType)
+			conditions.MarkFalse(obj, meta.ReadyCondition, v1alpha1.SnapshotArtifactTypeMismatchReason, err.Error(), []any{}...)
+			conditions.MarkStalled(obj, v1alpha1.SnapshotArtifactTypeMismatchReason, err.Error(), []any{}...)
+			event.New(r.EventRecorder, obj, nil, eventv1.EventSeverityError, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
 		tag := snapshot.Spec.Tag
 		if v, ok := snapshot.Spec.Identity[v1alpha1.ResourceHelmChartVersion]; ok {
 			tag = v
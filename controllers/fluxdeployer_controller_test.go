@@ -13,6 +13,7 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -25,9 +26,11 @@ import (
 
 func TestFluxDeployerReconcile(t *testing.T) {
 	testcases := []struct {
-		name     string
-		ready    bool
-		deployer *v1alpha1.FluxDeployer
+		name       string
+		ready      bool
+		encryption *v1alpha1.SnapshotEncryption
+		reason     string
+		deployer   *v1alpha1.FluxDeployer
 	}{
 		{
 			name:  "should be ready after reconciling",
@@ -79,6 +82,32 @@ func TestFluxDeployerReconcile(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "should refuse to deploy an encrypted snapshot",
+			ready: false,
+			encryption: &v1alpha1.SnapshotEncryption{
+				SecretRef: meta.LocalObjectReference{Name: "snapshot-key"},
+			},
+			reason: v1alpha1.EncryptedSnapshotNotSupportedReason,
+			deployer: &v1alpha1.FluxDeployer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deployer",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.FluxDeployerSpec{
+					SourceRef: v1alpha1.ObjectReference{
+						NamespacedObjectKindReference: meta.NamespacedObjectKindReference{
+							Name:      "test-resource",
+							Namespace: "default",
+							Kind:      "Resource",
+						},
+					},
+					KustomizationTemplate: &kustomizev1.KustomizationSpec{
+						Path: "bla",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -108,8 +137,9 @@ func TestFluxDeployerReconcile(t *testing.T) {
 						v1alpha1.ResourceVersionKey:       "v0.0.5",
 						v1alpha1.ResourceHelmChartVersion: "v0.0.5",
 					},
-					Digest: "digest-1",
-					Tag:    "1234",
+					Digest:     "digest-1",
+					Tag:        "1234",
+					Encryption: tc.encryption,
 				},
 				Status: v1alpha1.SnapshotStatus{
 					LastReconciledDigest: "digest-1",
@@ -147,12 +177,24 @@ func TestFluxDeployerReconcile(t *testing.T) {
 					Namespace: deployer.Namespace,
 				},
 			})
-			require.NoError(t, err)
+			if tc.reason != "" {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
 			assert.Equal(t, ctrl.Result{}, result)
 
 			require.NoError(t, client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: deployer.Name}, deployer))
 			assert.Equal(t, tc.ready, conditions.IsReady(deployer))
 
+			if tc.reason != "" {
+				assert.Equal(t, tc.reason, conditions.GetReason(deployer, meta.ReadyCondition))
+
+				ociRepository := &sourcev1.OCIRepository{}
+				err := client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: deployer.Name}, ociRepository)
+				assert.True(t, apierrors.IsNotFound(err), "no OCIRepository must be created for an encrypted snapshot")
+			}
+
 			close(recorder.Events)
 		})
 	}
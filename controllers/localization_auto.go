@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	ocmcore "ocm.software/ocm/api/ocm"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/access"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+	"github.com/open-component-model/ocm-controller/pkg/images"
+	"github.com/open-component-model/ocm-controller/pkg/substitute"
+)
+
+// ociImageType is the type of the resources images are localized with.
+const ociImageType = "ociImage"
+
+// autoLocalize adds a substitution for every image of the manifests which matches an ociImage resource of the
+// component version. It returns every image it found, unmatched ones with the reason.
+func (m *MutationReconcileLooper) autoLocalize(
+	ctx context.Context,
+	octx ocmcore.Context,
+	auto *configdata.AutoLocalization,
+	preferDigest bool,
+	sourceDir string,
+	compvers ocmcore.ComponentVersionAccess,
+	opts access.Options,
+	localizations *substitute.Substitutions,
+) ([]v1alpha1.ImageMatch, error) {
+	pattern := auto.File
+	if pattern == "" {
+		pattern = "**"
+	}
+
+	files, err := substitute.Files(sourceDir, pattern, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match files: %w", err)
+	}
+
+	refs, err := images.Find(sourceDir, files, auto.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find images: %w", err)
+	}
+
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	label := auto.Label
+	if label == "" {
+		label = configdata.DefaultImageLabel
+	}
+
+	resources, locations, err := imageResources(compvers, opts, label)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]v1alpha1.ImageMatch, 0, len(refs))
+	for _, ref := range refs {
+		match := v1alpha1.ImageMatch{File: ref.File, Kind: ref.Kind, Name: ref.Name, Path: ref.Path, Image: ref.Image}
+
+		i, err := images.Match(ref.Image, resources)
+		if err != nil {
+			match.Message = err.Error()
+			result = append(result, match)
+
+			continue
+		}
+
+		loc := locations[i]
+		image := loc.Image
+
+		if preferDigest {
+			// the digest is kept in the location, so it's only fetched once for every resource
+			if loc.Digest == "" {
+				if loc.Digest, err = m.OCMClient.GetImageDigest(ctx, octx, loc.Image); err != nil {
+					return nil, fmt.Errorf("failed to get digest of resource %s: %w", resources[i].Name, err)
+				}
+			}
+
+			image = loc.ImageWithDigest()
+		}
+
+		if err := localizations.Add("image", ref.Target(), ref.Path, image); err != nil {
+			return nil, fmt.Errorf("failed to add image: %w", err)
+		}
+
+		match.Resource = resources[i].Name
+		match.Localized = image
+		result = append(result, match)
+	}
+
+	return result, nil
+}
+
+// imageResources returns the ociImage resources of the component version with the value of their image label
+// and their locations.
+func imageResources(
+	compvers ocmcore.ComponentVersionAccess,
+	opts access.Options,
+	label string,
+) ([]images.Resource, []*access.Location, error) {
+	var (
+		resources []images.Resource
+		locations []*access.Location
+	)
+
+	for _, resource := range compvers.GetResources() {
+		meta := resource.Meta()
+		if meta.Type != ociImageType {
+			continue
+		}
+
+		accSpec, err := resource.Access()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get access of resource %s: %w", meta.Name, err)
+		}
+
+		spec, err := access.ToMap(accSpec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to convert access of resource %s: %w", meta.Name, err)
+		}
+
+		opts.Version = meta.Version
+
+		loc, err := access.NewRegistry().Resolve(spec, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve location of resource %s: %w", meta.Name, err)
+		}
+
+		value, _ := meta.Labels.AsMap()[label].(string)
+
+		resources = append(resources, images.Resource{Name: meta.Name, Label: value, Repository: loc.Repository})
+		locations = append(locations, loc)
+	}
+
+	return resources, locations, nil
+}
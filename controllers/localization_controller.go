@@ -14,6 +14,7 @@ import (
 	rreconcile "github.com/fluxcd/pkg/runtime/reconcile"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	mh "github.com/open-component-model/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/open-component-model/ocm-controller/api/v1alpha1"
 	"github.com/open-component-model/ocm-controller/pkg/cache"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
 	"github.com/open-component-model/ocm-controller/pkg/metrics"
 	"github.com/open-component-model/ocm-controller/pkg/ocm"
 	"github.com/open-component-model/ocm-controller/pkg/snapshot"
@@ -54,7 +56,10 @@ type LocalizationReconciler struct {
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=localizations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=localizations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=delivery.ocm.software,resources=localizations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=gitrepositories;buckets;ocirepositories;helmcharts,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=delivery.ocm.software,resources=fluxdeployers,verbs=get;list;watch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LocalizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -62,6 +67,7 @@ func (r *LocalizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		sourceKey      = ".metadata.source"
 		configKey      = ".metadata.config"
 		patchSourceKey = ".metadata.patchSource"
+		pullSecretKey  = ".status.pullSecret.sourceSecret"
 	)
 
 	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.Localization{}, sourceKey, func(rawObj client.Object) []string {
@@ -86,16 +92,21 @@ func (r *LocalizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return nil
 		}
 
-		if loc.Spec.ConfigRef == nil {
-			return nil
-		}
+		var keys []string
+		for _, step := range loc.Spec.GetSteps() {
+			if step.ConfigRef == nil {
+				continue
+			}
 
-		ns := loc.Spec.ConfigRef.Namespace
-		if ns == "" {
-			ns = loc.GetNamespace()
+			ns := step.ConfigRef.Namespace
+			if ns == "" {
+				ns = loc.GetNamespace()
+			}
+
+			keys = append(keys, fmt.Sprintf("%s/%s", ns, step.ConfigRef.Name))
 		}
 
-		return []string{fmt.Sprintf("%s/%s", ns, loc.Spec.ConfigRef.Name)}
+		return keys
 	}); err != nil {
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
@@ -106,20 +117,44 @@ func (r *LocalizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return nil
 		}
 
-		if loc.Spec.PatchStrategicMerge == nil {
-			return nil
+		var keys []string
+		for _, step := range loc.Spec.GetSteps() {
+			var ref *meta.NamespacedObjectKindReference
+			switch {
+			case step.PatchStrategicMerge != nil:
+				ref = &step.PatchStrategicMerge.Source.SourceRef
+			case step.Kustomize != nil && step.Kustomize.SourceRef != nil:
+				ref = step.Kustomize.SourceRef
+			default:
+				continue
+			}
+
+			ns := ref.Namespace
+			if ns == "" {
+				ns = loc.GetNamespace()
+			}
+
+			keys = append(keys, fmt.Sprintf("%s/%s", ns, ref.Name))
 		}
-		ns := loc.Spec.PatchStrategicMerge.Source.SourceRef.Namespace
-		if ns == "" {
-			ns = loc.GetNamespace()
+
+		return keys
+	}); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+
+	// the generated pull secret is updated when the secret it was generated from changes
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1alpha1.Localization{}, pullSecretKey, func(rawObj client.Object) []string {
+		loc, ok := rawObj.(*v1alpha1.Localization)
+		if !ok || loc.Status.PullSecret == nil {
+			return nil
 		}
 
-		return []string{fmt.Sprintf("%s/%s", ns, loc.Spec.PatchStrategicMerge.Source.SourceRef.Name)}
+		return []string{loc.Status.PullSecret.SourceSecret}
 	}); err != nil {
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Localization{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(
 			&v1alpha1.ComponentVersion{},
@@ -131,12 +166,23 @@ func (r *LocalizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			handler.EnqueueRequestsFromMapFunc(r.findObjects(sourceKey, configKey)),
 			builder.WithPredicates(SnapshotDigestChangedPredicate{}),
 		).
+		// the names of the secrets are enough to find the localizations, so their data isn't cached
 		Watches(
-			&sourcev1.GitRepository{},
-			handler.EnqueueRequestsFromMapFunc(r.findObjectsForGitRepository(patchSourceKey)),
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSource(pullSecretKey)),
+			builder.OnlyMetadata,
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		)
+
+	for _, source := range fluxSourceObjects() {
+		b = b.Watches(
+			source,
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSource(patchSourceKey, configKey)),
 			builder.WithPredicates(SourceRevisionChangePredicate{}),
-		).
-		Complete(r)
+		)
+	}
+
+	return b.Complete(r)
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -198,53 +244,80 @@ func (r *LocalizationReconciler) Reconcile(
 		return ctrl.Result{}, nil
 	}
 
-	if obj.Spec.ConfigRef != nil {
-		ready, err := r.checkReadiness(ctx, obj.GetNamespace(), obj.Spec.ConfigRef)
-		if err != nil {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.ConfigRefNotReadyWithErrorReason,
-				fmt.Sprintf("config ref not yet ready with error: %s: %s", obj.Spec.ConfigRef.Name, err),
-			)
-
-			// we are watching the source object which should re-trigger the reconcile loop
-			return ctrl.Result{}, nil
+	for _, step := range obj.Spec.GetSteps() {
+		if step.ConfigRef != nil {
+			ready, err := r.checkReadiness(ctx, obj.GetNamespace(), step.ConfigRef)
+			if err != nil {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.ConfigRefNotReadyWithErrorReason,
+					fmt.Sprintf("config ref not yet ready with error: %s: %s", step.ConfigRef.Name, err),
+				)
+
+				// we are watching the source object which should re-trigger the reconcile loop
+				return ctrl.Result{}, nil
+			}
+			if !ready {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.ConfigRefNotReadyReason,
+					fmt.Sprintf("config ref not yet ready: %s", step.ConfigRef.Name),
+				)
+
+				return ctrl.Result{}, nil
+			}
 		}
-		if !ready {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.ConfigRefNotReadyReason,
-				fmt.Sprintf("config ref not yet ready: %s", obj.Spec.ConfigRef.Name),
-			)
 
-			return ctrl.Result{}, nil
-		}
-	}
+		if step.PatchStrategicMerge != nil {
+			ready, err := r.checkSourceReadiness(ctx, step.PatchStrategicMerge.Source.SourceRef)
+			if err != nil {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.PatchStrategicMergeSourceRefNotReadyWithErrorReason,
+					fmt.Sprintf("patch strategic merge source ref not yet ready with error: %s: %s", step.PatchStrategicMerge.Source.SourceRef.Name, err),
+				)
 
-	if obj.Spec.PatchStrategicMerge != nil {
-		ready, err := r.checkSourceReadiness(ctx, obj.Spec.PatchStrategicMerge.Source.SourceRef)
-		if err != nil {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.PatchStrategicMergeSourceRefNotReadyWithErrorReason,
-				fmt.Sprintf("patch strategic merge source ref not yet ready with error: %s: %s", obj.Spec.PatchStrategicMerge.Source.SourceRef.Name, err),
-			)
+				return ctrl.Result{}, nil
+			}
 
-			return ctrl.Result{}, nil
+			if !ready {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.PatchStrategicMergeSourceRefNotReadyReason,
+					fmt.Sprintf("patch strategic merge source ref not yet ready: %s", step.PatchStrategicMerge.Source.SourceRef.Name),
+				)
+
+				return ctrl.Result{}, nil
+			}
 		}
 
-		if !ready {
-			status.MarkNotReady(
-				r.EventRecorder,
-				obj,
-				v1alpha1.PatchStrategicMergeSourceRefNotReadyReason,
-				fmt.Sprintf("patch strategic merge source ref not yet ready: %s", obj.Spec.PatchStrategicMerge.Source.SourceRef.Name),
-			)
+		if step.Kustomize != nil && step.Kustomize.SourceRef != nil {
+			ready, err := r.checkSourceReadiness(ctx, *step.Kustomize.SourceRef)
+			if err != nil {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.KustomizeSourceRefNotReadyWithErrorReason,
+					fmt.Sprintf("kustomize source ref not yet ready with error: %s: %s", step.Kustomize.SourceRef.Name, err),
+				)
 
-			return ctrl.Result{}, nil
+				return ctrl.Result{}, nil
+			}
+
+			if !ready {
+				status.MarkNotReady(
+					r.EventRecorder,
+					obj,
+					v1alpha1.KustomizeSourceRefNotReadyReason,
+					fmt.Sprintf("kustomize source ref not yet ready: %s", step.Kustomize.SourceRef.Name),
+				)
+
+				return ctrl.Result{}, nil
+			}
 		}
 	}
 
@@ -295,6 +368,18 @@ func (r *LocalizationReconciler) reconcile(
 			return ctrl.Result{}, err
 		}
 
+		if errors.Is(err, snapshot.ErrEncryption) {
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.SnapshotEncryptionFailedReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
+		if errors.Is(err, configdata.ErrInvalid) {
+			status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ConfigDataInvalidReason, err.Error())
+
+			return ctrl.Result{}, err
+		}
+
 		err = fmt.Errorf("failed to reconcile mutation object: %w", err)
 		status.MarkNotReady(r.EventRecorder, obj, v1alpha1.ReconcileMutationObjectFailedReason, err.Error())
 
@@ -352,18 +437,22 @@ func (r *LocalizationReconciler) findObjects(sourceKey, configKey string) handle
 	}
 }
 
-// this function will enqueue a reconciliation for any component version which is referenced
-// in the .spec.sourceRef or spec.configRef field of a Localization.
-func (r *LocalizationReconciler) findObjectsForGitRepository(key string) handler.MapFunc {
+// this function will enqueue a reconciliation for any Localization referencing the Flux source
+// or Secret in one of the indexed fields.
+func (r *LocalizationReconciler) findObjectsForSource(keys ...string) handler.MapFunc {
 	return func(ctx context.Context, obj client.Object) []reconcile.Request {
-		patchRefs := &v1alpha1.LocalizationList{}
-		if err := r.List(ctx, patchRefs, &client.ListOptions{
-			FieldSelector: fields.OneTermEqualSelector(key, client.ObjectKeyFromObject(obj).String()),
-		}); err != nil {
-			return []reconcile.Request{}
+		locs := &v1alpha1.LocalizationList{}
+		for _, key := range keys {
+			result := &v1alpha1.LocalizationList{}
+			if err := r.List(ctx, result, &client.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector(key, client.ObjectKeyFromObject(obj).String()),
+			}); err != nil {
+				return []reconcile.Request{}
+			}
+			locs.Items = append(locs.Items, result.Items...)
 		}
 
-		return makeRequestsForLocalizations(patchRefs.Items...)
+		return makeRequestsForLocalizations(locs.Items...)
 	}
 }
 
@@ -383,6 +472,14 @@ func (r *LocalizationReconciler) checkReadiness(
 			return false, fmt.Errorf("failed to find component version source: %w", err)
 		}
 
+	case sourcev1.GitRepositoryKind, sourcev1.BucketKind, sourcev1.OCIRepositoryKind, sourcev1.HelmChartKind:
+		if obj.Namespace == "" {
+			obj.Namespace = ns
+		}
+		ref, _ = newFluxSource(obj.Kind)
+		if err := r.Get(ctx, obj.GetObjectKey(), ref); err != nil {
+			return false, fmt.Errorf("failed to find flux source: %w", err)
+		}
 	default:
 		// if the APIVersion is not set then default to "delivery.ocm.software/v1alpha1"
 		if obj.APIVersion == "" {
@@ -426,8 +523,8 @@ func (r *LocalizationReconciler) checkSourceReadiness(
 ) (bool, error) {
 	var ref conditions.Getter
 	switch obj.Kind {
-	case sourcev1.GitRepositoryKind:
-		ref = &sourcev1.GitRepository{}
+	case sourcev1.GitRepositoryKind, sourcev1.BucketKind, sourcev1.OCIRepositoryKind, sourcev1.HelmChartKind:
+		ref, _ = newFluxSource(obj.Kind)
 	case v1alpha1.ResourceKind:
 		ref = &v1alpha1.Resource{}
 	case v1alpha1.ConfigurationKind:
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	ocmcore "ocm.software/ocm/api/ocm"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/access"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+// mappingOutput is a result of a mapping and the path it's substituted at.
+type mappingOutput struct {
+	path  string
+	value json.RawMessage
+}
+
+// mappingScope returns the fields CUE programs of mappings see besides the component: the repository URL of
+// the component version, the namespace and parameters of the localization and the resources of the component
+// version with their resolved access.
+func mappingScope(
+	obj v1alpha1.MutationObject,
+	compvers ocmcore.ComponentVersionAccess,
+	opts access.Options,
+) (map[string]any, error) {
+	parameters := map[string]any{}
+	if p := obj.GetSpec().Parameters; p != nil && len(p.Raw) > 0 {
+		if err := json.Unmarshal(p.Raw, &parameters); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal parameters: %w", err)
+		}
+	}
+
+	resources, err := mappingResources(compvers, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"repository": opts.RepositoryURL,
+		"namespace":  obj.GetNamespace(),
+		"parameters": parameters,
+		"resources":  resources,
+	}, nil
+}
+
+// mappingResources returns the resources of the component version with their access as it's resolved by OCM
+// and, if the access type is known, the location it points to, e.g. the registry, repository and tag of an image.
+func mappingResources(compvers ocmcore.ComponentVersionAccess, opts access.Options) ([]any, error) {
+	resources := make([]any, 0, len(compvers.GetResources()))
+	for _, resource := range compvers.GetResources() {
+		meta := resource.Meta()
+
+		accSpec, err := resource.Access()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get access of resource %s: %w", meta.Name, err)
+		}
+
+		spec, err := access.ToMap(accSpec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert access of resource %s: %w", meta.Name, err)
+		}
+
+		item := map[string]any{
+			"name":          meta.Name,
+			"version":       meta.Version,
+			"type":          meta.Type,
+			"extraIdentity": meta.ExtraIdentity,
+			"access":        spec,
+		}
+
+		// resources with access types which don't point to a location, e.g. local files, have no location
+		opts.Version = meta.Version
+		if loc, err := access.NewRegistry().Resolve(spec, opts); err == nil {
+			item["location"] = map[string]any{
+				"registry":                 loc.Registry,
+				"repository":               loc.Repository,
+				"fullyQualifiedRepository": loc.FullyQualifiedRepository(),
+				"image":                    loc.Image,
+				"tag":                      loc.Tag,
+				"digest":                   loc.Digest,
+				"imageWithDigest":          loc.ImageWithDigest(),
+				"url":                      loc.URL,
+			}
+		}
+
+		resources = append(resources, item)
+	}
+
+	return resources, nil
+}
+
+// mappingOutputs returns the results of the evaluated program of the mapping. The out field is a JSON encoded
+// string, the fields of the outputs are encoded as they are.
+func mappingOutputs(v cue.Value, mapping *configdata.Mapping) ([]mappingOutput, error) {
+	if err := v.Err(); err != nil {
+		return nil, fmt.Errorf("failed to compile transform: %w", err)
+	}
+
+	var outputs []mappingOutput
+
+	if mapping.Path != "" {
+		res, err := v.LookupPath(cue.ParsePath("out")).Bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		var out json.RawMessage
+		if err := json.Unmarshal(res, &out); err != nil {
+			return nil, err
+		}
+
+		outputs = append(outputs, mappingOutput{path: mapping.Path, value: out})
+	}
+
+	for _, output := range mapping.Outputs {
+		field := v.LookupPath(cue.ParsePath(output.Field))
+		if !field.Exists() {
+			return nil, fmt.Errorf("output field %s not found", output.Field)
+		}
+
+		if err := field.Validate(cue.Concrete(true)); err != nil {
+			return nil, fmt.Errorf("output field %s is invalid: %w", output.Field, err)
+		}
+
+		value, err := field.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal output field %s: %w", output.Field, err)
+		}
+
+		outputs = append(outputs, mappingOutput{path: output.Path, value: value})
+	}
+
+	return outputs, nil
+}
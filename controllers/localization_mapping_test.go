@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+func TestMappingOutputs(t *testing.T) {
+	cueCtx := cuecontext.New()
+	root := cueCtx.Encode(map[string]any{
+		"namespace":  "podinfo",
+		"parameters": map[string]any{"env": "prod"},
+		"resources": []any{
+			map[string]any{"name": "image", "location": map[string]any{"registry": "ghcr.io", "tag": "6.1.0"}},
+		},
+	})
+
+	v := cueCtx.CompileString(`
+import "encoding/json"
+
+locations: {for r in resources {(r.name): r.location}}
+labels: {
+	env:    parameters.env
+	target: namespace
+}
+out: json.Marshal(locations.image.registry)
+`, cue.Scope(root))
+
+	outputs, err := mappingOutputs(v, &configdata.Mapping{
+		Path: "spec.registry",
+		Outputs: []configdata.MappingOutput{
+			{Field: "locations.image.tag", Path: "spec.values.image.tag"},
+			{Field: "labels", Path: "metadata.labels"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, outputs, 3)
+	assert.Equal(t, "spec.registry", outputs[0].path)
+	assert.JSONEq(t, `"ghcr.io"`, string(outputs[0].value))
+	assert.Equal(t, "spec.values.image.tag", outputs[1].path)
+	assert.JSONEq(t, `"6.1.0"`, string(outputs[1].value))
+	assert.Equal(t, "metadata.labels", outputs[2].path)
+	assert.JSONEq(t, `{"env": "prod", "target": "podinfo"}`, string(outputs[2].value))
+
+	_, err = mappingOutputs(v, &configdata.Mapping{
+		Outputs: []configdata.MappingOutput{{Field: "images", Path: "spec.images"}},
+	})
+	assert.ErrorContains(t, err, "output field images not found")
+}
@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/access"
+	"github.com/open-component-model/ocm-controller/pkg/images"
+	"github.com/open-component-model/ocm-controller/pkg/pullsecret"
+	"github.com/open-component-model/ocm-controller/pkg/substitute"
+)
+
+// pullSecretSuffix is appended to the name of a localization to name its generated pull secret.
+const pullSecretSuffix = "-pull-secret"
+
+// applyPullSecret creates or updates the pull secret of the localization with the credentials for the
+// registries images were localized to and adds it to the imagePullSecrets of the workloads pulling from them.
+// Workloads deployed to another namespace can't reference the pull secret, they are listed in the status.
+func (m *MutationReconcileLooper) applyPullSecret(
+	ctx context.Context,
+	obj *v1alpha1.Localization,
+	cv *v1alpha1.ComponentVersion,
+	sourceDir string,
+) error {
+	files, err := substitute.Files(sourceDir, "**", nil)
+	if err != nil {
+		return fmt.Errorf("failed to match files: %w", err)
+	}
+
+	refs, err := images.Find(sourceDir, files, nil)
+	if err != nil {
+		return fmt.Errorf("failed to find images: %w", err)
+	}
+
+	localized := localizedRegistries(m.locationOptions(obj.GetSpec(), cv))
+
+	targets, err := m.targetNamespaces(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	var (
+		workloads  []images.Reference
+		skipped    []string
+		registries []string
+	)
+
+	for _, ref := range refs {
+		image, err := name.ParseReference(ref.Image)
+		if err != nil || !slices.Contains(localized, image.Context().RegistryStr()) {
+			continue
+		}
+
+		if registry := image.Context().RegistryStr(); !slices.Contains(registries, registry) {
+			registries = append(registries, registry)
+		}
+
+		namespaces := otherNamespaces(ref, targets, obj.GetNamespace())
+		if len(namespaces) == 0 {
+			workloads = append(workloads, ref)
+
+			continue
+		}
+
+		for _, namespace := range namespaces {
+			if workload := fmt.Sprintf("%s %s/%s", ref.Kind, namespace, ref.Name); !slices.Contains(skipped, workload) {
+				skipped = append(skipped, workload)
+			}
+		}
+	}
+
+	if len(registries) == 0 {
+		return nil
+	}
+
+	slices.Sort(registries)
+
+	spec := obj.Spec.PullSecret
+
+	sourceKey, err := pullSecretSource(spec, obj, cv)
+	if err != nil {
+		return err
+	}
+
+	source := &corev1.Secret{}
+	if err := m.Client.Get(ctx, sourceKey, source); err != nil {
+		return fmt.Errorf("failed to get secret %s: %w", sourceKey, err)
+	}
+
+	data, err := pullsecret.Generate(source.Data, registries)
+	if err != nil {
+		return fmt.Errorf("failed to generate pull secret from secret %s: %w", sourceKey, err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmp.Or(spec.Name, obj.GetName()+pullSecretSuffix),
+			Namespace: obj.GetNamespace(),
+		},
+	}
+
+	owner := client.ObjectKeyFromObject(obj).String()
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, m.Client, secret, func() error {
+		if secret.ResourceVersion != "" && secret.Annotations[v1alpha1.PullSecretOwnerAnnotation] != owner {
+			return fmt.Errorf("secret %s already exists and wasn't generated for this localization", client.ObjectKeyFromObject(secret))
+		}
+
+		if err := controllerutil.SetOwnerReference(obj, secret, m.Scheme); err != nil {
+			return fmt.Errorf("failed to set owner reference: %w", err)
+		}
+
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+
+		secret.Annotations[v1alpha1.PullSecretOwnerAnnotation] = owner
+		secret.Type = corev1.SecretTypeDockerConfigJson
+		secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: data}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to create or update pull secret: %w", err)
+	}
+
+	if err := images.InjectPullSecret(sourceDir, workloads, secret.Name); err != nil {
+		return err
+	}
+
+	obj.GetStatus().PullSecret = &v1alpha1.PullSecretStatus{
+		Name:             secret.Name,
+		Namespace:        secret.Namespace,
+		SourceSecret:     sourceKey.String(),
+		Registries:       registries,
+		SkippedWorkloads: skipped,
+	}
+
+	return nil
+}
+
+// targetNamespaces returns the target namespaces of the FluxDeployers deploying the localization.
+func (m *MutationReconcileLooper) targetNamespaces(ctx context.Context, obj *v1alpha1.Localization) ([]string, error) {
+	deployers := &v1alpha1.FluxDeployerList{}
+	if err := m.Client.List(ctx, deployers); err != nil {
+		return nil, fmt.Errorf("failed to list flux deployers: %w", err)
+	}
+
+	var namespaces []string
+
+	for _, deployer := range deployers.Items {
+		ref := deployer.Spec.SourceRef
+		if ref.Kind != v1alpha1.LocalizationKind || ref.Name != obj.GetName() ||
+			cmp.Or(ref.Namespace, deployer.GetNamespace()) != obj.GetNamespace() {
+			continue
+		}
+
+		if template := deployer.Spec.KustomizationTemplate; template != nil && template.TargetNamespace != "" {
+			namespaces = append(namespaces, template.TargetNamespace)
+		}
+
+		if template := deployer.Spec.HelmReleaseTemplate; template != nil && template.TargetNamespace != "" {
+			namespaces = append(namespaces, template.TargetNamespace)
+		}
+	}
+
+	return namespaces, nil
+}
+
+// otherNamespaces returns the namespaces other than the namespace of the localization a workload is deployed
+// to. The target namespaces of FluxDeployers override the namespace of the document, documents without a
+// namespace are deployed to the namespace of the localization.
+func otherNamespaces(ref images.Reference, targets []string, namespace string) []string {
+	if len(targets) == 0 {
+		targets = []string{cmp.Or(ref.Namespace, namespace)}
+	}
+
+	var namespaces []string
+
+	for _, target := range targets {
+		if target != namespace && !slices.Contains(namespaces, target) {
+			namespaces = append(namespaces, target)
+		}
+	}
+
+	return namespaces
+}
+
+// pullSecretSource returns the secret the credentials of the pull secret are taken from. The secrets of the
+// component version are only used if it's in the namespace of the localization, the pull secret would expose
+// them to that namespace otherwise.
+func pullSecretSource(spec *v1alpha1.PullSecret, obj v1alpha1.MutationObject, cv *v1alpha1.ComponentVersion) (types.NamespacedName, error) {
+	if spec.SecretRef != nil {
+		return types.NamespacedName{Namespace: obj.GetNamespace(), Name: spec.SecretRef.Name}, nil
+	}
+
+	if cv.GetNamespace() != obj.GetNamespace() {
+		return types.NamespacedName{}, fmt.Errorf(
+			"credentials of component version %s in another namespace can't be used, secretRef must be set",
+			client.ObjectKeyFromObject(cv),
+		)
+	}
+
+	switch {
+	case cv.Spec.Destination != nil && cv.Spec.Destination.SecretRef != nil:
+		return types.NamespacedName{Namespace: cv.GetNamespace(), Name: cv.Spec.Destination.SecretRef.Name}, nil
+	case cv.Spec.Destination == nil && cv.Spec.Repository.SecretRef != nil:
+		return types.NamespacedName{Namespace: cv.GetNamespace(), Name: cv.Spec.Repository.SecretRef.Name}, nil
+	default:
+		return types.NamespacedName{}, errors.New("no secret with credentials, neither secretRef nor a secret of the component version repository is set")
+	}
+}
+
+// localizedRegistries returns the registries images are localized to: the registry of the repository of the
+// component version and the registries of the mirrors.
+func localizedRegistries(opts access.Options) []string {
+	var registries []string
+
+	repository := opts.RepositoryURL
+	if u, err := url.Parse(repository); err == nil && u.Host != "" {
+		repository = u.Host + u.Path
+	}
+
+	if registry, _, _ := strings.Cut(repository, "/"); registry != "" {
+		registries = append(registries, registry)
+	}
+
+	for _, mirror := range opts.Mirrors {
+		registry, _, _ := strings.Cut(mirror.Target, "/")
+		registries = append(registries, registry)
+	}
+
+	return registries
+}
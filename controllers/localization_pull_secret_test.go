@@ -0,0 +1,394 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/access"
+	"github.com/open-component-model/ocm-controller/pkg/pullsecret"
+)
+
+// pullSecretManifests has a workload pulling from the registry of the component version and one pulling a
+// public image.
+const pullSecretManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      containers:
+        - name: podinfo
+          image: registry.local:5000/ocm/podinfo:6.3.5
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: public
+spec:
+  template:
+    spec:
+      containers:
+        - name: busybox
+          image: docker.io/library/busybox:1.36
+`
+
+func TestLocalizedRegistries(t *testing.T) {
+	assert.Equal(t, []string{"registry.local:5000", "mirror.internal"}, localizedRegistries(access.Options{
+		RepositoryURL: "https://registry.local:5000/ocm",
+		Mirrors:       []access.Mirror{{Source: "ghcr.io/org", Target: "mirror.internal/team-a"}},
+	}))
+	assert.Equal(t, []string{"ghcr.io"}, localizedRegistries(access.Options{RepositoryURL: "ghcr.io/org/components"}))
+}
+
+func TestApplyPullSecret(t *testing.T) {
+	testCases := []struct {
+		name           string
+		pullSecret     *v1alpha1.PullSecret
+		cv             func(cv *v1alpha1.ComponentVersion)
+		objects        []client.Object
+		expectedName   string
+		expectedSource string
+		expectedUser   string
+		expectError    string
+	}{
+		{
+			name:           "credentials of the repository of the component version",
+			pullSecret:     &v1alpha1.PullSecret{},
+			objects:        []client.Object{credentialsSecret("default", "repository-credentials", "repository")},
+			expectedName:   "podinfo-pull-secret",
+			expectedSource: "default/repository-credentials",
+			expectedUser:   "repository",
+		},
+		{
+			name:       "credentials of the destination of the component version",
+			pullSecret: &v1alpha1.PullSecret{Name: "registry"},
+			cv: func(cv *v1alpha1.ComponentVersion) {
+				cv.Spec.Destination = &v1alpha1.Repository{
+					URL:       "registry.local:5000/ocm",
+					SecretRef: &corev1.LocalObjectReference{Name: "destination-credentials"},
+				}
+			},
+			objects: []client.Object{
+				credentialsSecret("default", "repository-credentials", "repository"),
+				credentialsSecret("default", "destination-credentials", "destination"),
+			},
+			expectedName:   "registry",
+			expectedSource: "default/destination-credentials",
+			expectedUser:   "destination",
+		},
+		{
+			name: "credentials of the secretRef",
+			pullSecret: &v1alpha1.PullSecret{
+				SecretRef: &meta.LocalObjectReference{Name: "registry-credentials"},
+			},
+			objects: []client.Object{
+				credentialsSecret("default", "repository-credentials", "repository"),
+				credentialsSecret("default", "registry-credentials", "registry"),
+			},
+			expectedName:   "podinfo-pull-secret",
+			expectedSource: "default/registry-credentials",
+			expectedUser:   "registry",
+		},
+		{
+			name: "credentials of the secretRef with a component version in another namespace",
+			pullSecret: &v1alpha1.PullSecret{
+				SecretRef: &meta.LocalObjectReference{Name: "registry-credentials"},
+			},
+			cv: func(cv *v1alpha1.ComponentVersion) {
+				cv.Namespace = "ocm-system"
+			},
+			objects:        []client.Object{credentialsSecret("default", "registry-credentials", "registry")},
+			expectedName:   "podinfo-pull-secret",
+			expectedSource: "default/registry-credentials",
+			expectedUser:   "registry",
+		},
+		{
+			name:       "credentials of a component version in another namespace",
+			pullSecret: &v1alpha1.PullSecret{},
+			cv: func(cv *v1alpha1.ComponentVersion) {
+				cv.Namespace = "ocm-system"
+			},
+			objects:     []client.Object{credentialsSecret("ocm-system", "repository-credentials", "repository")},
+			expectError: "credentials of component version ocm-system/test-component in another namespace can't be used, secretRef must be set",
+		},
+		{
+			name:       "no credentials",
+			pullSecret: &v1alpha1.PullSecret{},
+			cv: func(cv *v1alpha1.ComponentVersion) {
+				cv.Spec.Repository.SecretRef = nil
+			},
+			expectError: "no secret with credentials",
+		},
+		{
+			name:       "existing secret which wasn't generated",
+			pullSecret: &v1alpha1.PullSecret{},
+			objects: []client.Object{
+				credentialsSecret("default", "repository-credentials", "repository"),
+				credentialsSecret("default", "podinfo-pull-secret", "other"),
+			},
+			expectError: "secret default/podinfo-pull-secret already exists and wasn't generated for this localization",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sourceDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "deploy.yaml"), []byte(pullSecretManifests), 0o600))
+
+			cv := pullSecretComponentVersion()
+			if tc.cv != nil {
+				tc.cv(cv)
+			}
+
+			obj := pullSecretLocalization(tc.pullSecret)
+			m := &MutationReconcileLooper{
+				Client: env.FakeKubeClient(WithObjects(tc.objects...)),
+				Scheme: env.scheme,
+			}
+
+			err := m.applyPullSecret(context.Background(), obj, cv, sourceDir)
+			if tc.expectError != "" {
+				assert.ErrorContains(t, err, tc.expectError)
+				assert.Nil(t, obj.Status.PullSecret)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, &v1alpha1.PullSecretStatus{
+				Name:         tc.expectedName,
+				Namespace:    "default",
+				SourceSecret: tc.expectedSource,
+				Registries:   []string{"registry.local:5000"},
+			}, obj.Status.PullSecret)
+
+			assertPullSecret(t, m.Client, obj, tc.expectedName, tc.expectedUser)
+			assertInjectedPullSecret(t, sourceDir, tc.expectedName)
+		})
+	}
+}
+
+func TestApplyPullSecretUpdate(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "deploy.yaml"), []byte(pullSecretManifests), 0o600))
+
+	// a secret generated for the localization without an owner reference
+	generated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "podinfo-pull-secret",
+			Namespace:   "default",
+			Annotations: map[string]string{v1alpha1.PullSecretOwnerAnnotation: "default/podinfo"},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {}}`)},
+	}
+	source := credentialsSecret("default", "repository-credentials", "repository")
+
+	obj := pullSecretLocalization(&v1alpha1.PullSecret{})
+	m := &MutationReconcileLooper{
+		Client: env.FakeKubeClient(WithObjects(generated, source)),
+		Scheme: env.scheme,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, m.applyPullSecret(ctx, obj, pullSecretComponentVersion(), sourceDir))
+	assertPullSecret(t, m.Client, obj, "podinfo-pull-secret", "repository")
+
+	// the credentials change, the secret is updated and added to the workloads only once
+	require.NoError(t, m.Client.Get(ctx, client.ObjectKeyFromObject(source), source))
+	source.Data[pullsecret.UsernameKey] = []byte("rotated")
+	require.NoError(t, m.Client.Update(ctx, source))
+
+	require.NoError(t, m.applyPullSecret(ctx, obj, pullSecretComponentVersion(), sourceDir))
+	assertPullSecret(t, m.Client, obj, "podinfo-pull-secret", "rotated")
+	assertInjectedPullSecret(t, sourceDir, "podinfo-pull-secret")
+}
+
+func TestApplyPullSecretWithoutLocalizedImages(t *testing.T) {
+	sourceDir := t.TempDir()
+	manifests := strings.ReplaceAll(pullSecretManifests, "registry.local:5000/ocm/", "ghcr.io/org/")
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "deploy.yaml"), []byte(manifests), 0o600))
+
+	obj := pullSecretLocalization(&v1alpha1.PullSecret{})
+	m := &MutationReconcileLooper{
+		Client: env.FakeKubeClient(WithObjects(credentialsSecret("default", "repository-credentials", "repository"))),
+		Scheme: env.scheme,
+	}
+
+	require.NoError(t, m.applyPullSecret(context.Background(), obj, pullSecretComponentVersion(), sourceDir))
+	assert.Nil(t, obj.Status.PullSecret)
+
+	secrets := &corev1.SecretList{}
+	require.NoError(t, m.Client.List(context.Background(), secrets))
+	assert.Len(t, secrets.Items, 1)
+
+	content, err := os.ReadFile(filepath.Join(sourceDir, "deploy.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, manifests, string(content))
+}
+
+func TestApplyPullSecretOtherNamespaces(t *testing.T) {
+	testCases := []struct {
+		name            string
+		namespace       string
+		targetNamespace string
+		expectedSkipped []string
+	}{
+		{
+			name:            "namespace of the workload",
+			namespace:       "apps",
+			expectedSkipped: []string{"Deployment apps/podinfo"},
+		},
+		{
+			name:            "target namespace of a flux deployer",
+			targetNamespace: "apps",
+			expectedSkipped: []string{"Deployment apps/podinfo"},
+		},
+		{
+			name:            "target namespace of a flux deployer overrides the namespace of the workload",
+			namespace:       "apps",
+			targetNamespace: "default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sourceDir := t.TempDir()
+			manifests := pullSecretManifests
+			if tc.namespace != "" {
+				manifests = strings.Replace(manifests, "name: podinfo\n", "name: podinfo\n  namespace: "+tc.namespace+"\n", 1)
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "deploy.yaml"), []byte(manifests), 0o600))
+
+			objects := []client.Object{credentialsSecret("default", "repository-credentials", "repository")}
+			if tc.targetNamespace != "" {
+				objects = append(objects, &v1alpha1.FluxDeployer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "podinfo",
+						Namespace: "default",
+					},
+					Spec: v1alpha1.FluxDeployerSpec{
+						SourceRef: v1alpha1.ObjectReference{
+							NamespacedObjectKindReference: meta.NamespacedObjectKindReference{
+								Kind: v1alpha1.LocalizationKind,
+								Name: "podinfo",
+							},
+						},
+						KustomizationTemplate: &kustomizev1.KustomizationSpec{
+							TargetNamespace: tc.targetNamespace,
+						},
+					},
+				})
+			}
+
+			obj := pullSecretLocalization(&v1alpha1.PullSecret{})
+			m := &MutationReconcileLooper{
+				Client: env.FakeKubeClient(WithObjects(objects...)),
+				Scheme: env.scheme,
+			}
+
+			require.NoError(t, m.applyPullSecret(context.Background(), obj, pullSecretComponentVersion(), sourceDir))
+			require.NotNil(t, obj.Status.PullSecret)
+			assert.Equal(t, tc.expectedSkipped, obj.Status.PullSecret.SkippedWorkloads)
+
+			content, err := os.ReadFile(filepath.Join(sourceDir, "deploy.yaml"))
+			require.NoError(t, err)
+
+			if len(tc.expectedSkipped) > 0 {
+				assert.Equal(t, manifests, string(content))
+
+				return
+			}
+
+			assertInjectedPullSecret(t, sourceDir, "podinfo-pull-secret")
+		})
+	}
+}
+
+func pullSecretLocalization(spec *v1alpha1.PullSecret) *v1alpha1.Localization {
+	return &v1alpha1.Localization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "podinfo",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.LocalizationSpec{
+			PullSecret: spec,
+		},
+	}
+}
+
+func pullSecretComponentVersion() *v1alpha1.ComponentVersion {
+	cv := DefaultComponent.DeepCopy()
+	cv.Spec.Repository = v1alpha1.Repository{
+		URL:       "registry.local:5000/ocm",
+		SecretRef: &corev1.LocalObjectReference{Name: "repository-credentials"},
+	}
+	cv.Status.ReplicatedRepositoryURL = "registry.local:5000/ocm"
+
+	return cv
+}
+
+func credentialsSecret(namespace, name, username string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			pullsecret.UsernameKey: []byte(username),
+			pullsecret.PasswordKey: []byte("password"),
+		},
+	}
+}
+
+// assertPullSecret asserts the generated secret is owned by the localization and holds the credentials of the
+// user for the registry of the component version.
+func assertPullSecret(t *testing.T, c client.Client, obj *v1alpha1.Localization, name, username string) {
+	t.Helper()
+
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, secret))
+
+	assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+	assert.Equal(t, "default/podinfo", secret.Annotations[v1alpha1.PullSecretOwnerAnnotation])
+	require.Len(t, secret.OwnerReferences, 1)
+	assert.Equal(t, "Localization", secret.OwnerReferences[0].Kind)
+	assert.Equal(t, obj.Name, secret.OwnerReferences[0].Name)
+
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":password"))
+	assert.JSONEq(t, fmt.Sprintf(
+		`{"auths": {"registry.local:5000": {"username": %q, "password": "password", "auth": %q}}}`, username, auth,
+	), string(secret.Data[corev1.DockerConfigJsonKey]))
+}
+
+// assertInjectedPullSecret asserts the secret was added once to the workload pulling from the registry of the
+// component version and not to the one pulling a public image.
+func assertInjectedPullSecret(t *testing.T, sourceDir, name string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(sourceDir, "deploy.yaml"))
+	require.NoError(t, err)
+
+	docs := strings.Split(string(content), "\n---\n")
+	require.Len(t, docs, 2)
+	assert.Contains(t, docs[0], "imagePullSecrets:")
+	assert.Equal(t, 1, strings.Count(docs[0], "- name: "+name+"\n"))
+	assert.NotContains(t, docs[1], "imagePullSecrets")
+}
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"cuelang.org/go/cue"
@@ -21,7 +25,7 @@ import (
 	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/fluxcd/pkg/tar"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
-	"github.com/google/go-containerregistry/pkg/name"
+	ocmerrors "github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/spiff/spiffing"
 	"github.com/mandelsoft/vfs/pkg/osfs"
 	corev1 "k8s.io/api/core/v1"
@@ -38,28 +42,40 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/yaml"
 
-	"github.com/open-component-model/ocm-controller/pkg/snapshot"
+	ocmsnapshot "github.com/open-component-model/ocm-controller/pkg/snapshot"
 	"github.com/open-component-model/ocm-controller/pkg/untar"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
-	"ocm.software/ocm/api/ocm/extensions/accessmethods/localblob"
-	"ocm.software/ocm/api/ocm/extensions/accessmethods/ociartifact"
-	"ocm.software/ocm/api/ocm/extensions/accessmethods/ociblob"
 	"ocm.software/ocm/api/ocm/ocmutils/localize"
 	ocmruntime "ocm.software/ocm/api/utils/runtime"
 	"ocm.software/ocm/api/utils/spiff"
 
 	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/access"
 	"github.com/open-component-model/ocm-controller/pkg/cache"
 	"github.com/open-component-model/ocm-controller/pkg/component"
 	"github.com/open-component-model/ocm-controller/pkg/configdata"
+	"github.com/open-component-model/ocm-controller/pkg/helm"
 	"github.com/open-component-model/ocm-controller/pkg/ocm"
+	"github.com/open-component-model/ocm-controller/pkg/redact"
+	"github.com/open-component-model/ocm-controller/pkg/sops"
+	"github.com/open-component-model/ocm-controller/pkg/substitute"
+	"github.com/open-component-model/ocm-controller/pkg/values"
 )
 
 // errTar defines an error that occurs when the resource is not a tar archive.
 var errTar = errors.New("expected tarred directory content for configuration/localization resources, got plain text")
 
+// helmValuesSubstitution names the substitution evaluating the helm values overlay of a ConfigData.
+const helmValuesSubstitution = "helm-values"
+
+// helmValuesRule names the helm values overlay in the matched files of the status.
+const helmValuesRule = "configuration.helmValues"
+
+// errDecryption defines an error that occurs when encrypted values can't be decrypted.
+var errDecryption = errors.New("failed to decrypt values")
+
 // MutationReconcileLooper holds dependencies required to reconcile a mutation object.
 type MutationReconcileLooper struct {
 	Scheme         *runtime.Scheme
@@ -67,11 +83,26 @@ type MutationReconcileLooper struct {
 	Client         client.Client
 	Cache          cache.Cache
 	DynamicClient  dynamic.Interface
-	SnapshotWriter snapshot.Writer
+	SnapshotWriter ocmsnapshot.Writer
+	// RegistryMirrors are the mirrors of every localization, see v1alpha1.RegistryMirror.
+	RegistryMirrors []access.Mirror
 }
 
 // ReconcileMutationObject reconciles mutation objects and writes a snapshot to the cache.
+// Sensitive values used during the mutation are masked in the returned error. They are collected by the
+// redactor of the context, so callers can mask them in anything else they derive from the error.
 func (m *MutationReconcileLooper) ReconcileMutationObject(ctx context.Context, obj v1alpha1.MutationObject) (int64, error) {
+	redactor := redact.FromContext(ctx)
+
+	size, err := m.reconcileMutationObject(redact.NewContext(ctx, redactor), obj)
+	if err != nil {
+		return -1, redactor.Error(err)
+	}
+
+	return size, nil
+}
+
+func (m *MutationReconcileLooper) reconcileMutationObject(ctx context.Context, obj v1alpha1.MutationObject) (int64, error) {
 	mutationSpec := obj.GetSpec()
 
 	sourceData, err := m.getData(ctx, &mutationSpec.SourceRef)
@@ -90,6 +121,11 @@ func (m *MutationReconcileLooper) ReconcileMutationObject(ctx context.Context, o
 		return -1, fmt.Errorf("source resource data cannot be empty")
 	}
 
+	artifact, err := m.getArtifactMetadata(ctx, &mutationSpec.SourceRef)
+	if err != nil {
+		return -1, fmt.Errorf("failed to get artifact metadata for source ref: %w", err)
+	}
+
 	sourceDir, snapshotID, err := m.performMutation(ctx, obj, mutationSpec, sourceData)
 	if err != nil {
 		return -1, err
@@ -97,7 +133,16 @@ func (m *MutationReconcileLooper) ReconcileMutationObject(ctx context.Context, o
 
 	defer os.RemoveAll(sourceDir)
 
-	digest, size, err := m.SnapshotWriter.Write(ctx, obj, sourceDir, snapshotID)
+	// the artifact of the source describes a chart, the snapshot holds the rendered manifests
+	if renderer := renderedBy(mutationSpec.GetSteps()); renderer != "" {
+		if artifact == nil {
+			artifact = &v1alpha1.ArtifactMetadata{}
+		}
+
+		artifact.RenderedBy = renderer
+	}
+
+	digest, size, err := m.SnapshotWriter.Write(ctx, obj, sourceDir, snapshotID, artifact, mutationSpec.SnapshotEncryption)
 	if err != nil {
 		return -1, fmt.Errorf("error writing snapshot: %w", err)
 	}
@@ -107,46 +152,204 @@ func (m *MutationReconcileLooper) ReconcileMutationObject(ctx context.Context, o
 	return size, nil
 }
 
+// performMutation applies the mutation steps in order. Each step mutates the output of the step before it,
+// the returned directory holds the output of the last step.
 func (m *MutationReconcileLooper) performMutation(
 	ctx context.Context,
 	obj v1alpha1.MutationObject,
 	mutationSpec *v1alpha1.MutationSpec,
 	sourceData []byte,
 ) (string, ocmmetav1.Identity, error) {
+	if len(mutationSpec.Steps) > 0 && (mutationSpec.ConfigRef != nil || mutationSpec.PatchStrategicMerge != nil) {
+		return "", ocmmetav1.Identity{}, errors.New("steps can't be combined with configRef or patchStrategicMerge")
+	}
+
 	var (
-		snapshotID ocmmetav1.Identity
 		sourceDir  string
-		err        error
+		identities []ocmmetav1.Identity
 	)
 
-	if mutationSpec.ConfigRef != nil {
-		sourceDir, snapshotID, err = m.mutateConfigRef(ctx, obj, mutationSpec, sourceData)
+	// every step adds the files its rules matched and the rules it skipped
+	obj.GetStatus().MatchedFiles = nil
+	obj.GetStatus().SkippedRules = nil
+	obj.GetStatus().Images = nil
+	obj.GetStatus().PullSecret = nil
+	obj.GetStatus().SchemaViolations = nil
+	obj.GetStatus().Profile = ""
+
+	for i, step := range mutationSpec.GetSteps() {
+		if sourceDir != "" {
+			data, err := ocmsnapshot.TarDirectory(sourceDir)
+			if err != nil {
+				return "", ocmmetav1.Identity{}, fmt.Errorf("failed to archive output of step %d: %w", i-1, err)
+			}
+
+			if err := os.RemoveAll(sourceDir); err != nil {
+				return "", ocmmetav1.Identity{}, fmt.Errorf("failed to remove output of step %d: %w", i-1, err)
+			}
+
+			sourceData = data
+		}
+
+		dir, identity, err := m.performMutationStep(ctx, obj, mutationSpec, i, step, sourceData)
 		if err != nil {
-			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to apply config ref: %w", err)
+			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to apply step %d: %w", i, err)
 		}
+
+		sourceDir = dir
+		identities = append(identities, identity)
+	}
+
+	snapshotID, err := compositeIdentity(identities)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
 	}
 
-	if mutationSpec.PatchStrategicMerge != nil {
-		sourceDir, snapshotID, err = m.mutatePatchStrategicMerge(ctx, obj, mutationSpec, sourceData)
+	return sourceDir, snapshotID, nil
+}
+
+// qualifyRules prefixes the names of the rules a step added to the status after the given offsets.
+func qualifyRules(status *v1alpha1.MutationStatus, prefix string, matched, skipped int) {
+	for i := matched; i < len(status.MatchedFiles); i++ {
+		status.MatchedFiles[i].Rule = prefix + status.MatchedFiles[i].Rule
+	}
+
+	for i := skipped; i < len(status.SkippedRules); i++ {
+		status.SkippedRules[i].Rule = prefix + status.SkippedRules[i].Rule
+	}
+}
+
+func (m *MutationReconcileLooper) performMutationStep(
+	ctx context.Context,
+	obj v1alpha1.MutationObject,
+	mutationSpec *v1alpha1.MutationSpec,
+	index int,
+	step v1alpha1.MutationStep,
+	sourceData []byte,
+) (string, ocmmetav1.Identity, error) {
+	set := 0
+	for _, mutation := range []bool{
+		step.ConfigRef != nil,
+		step.PatchStrategicMerge != nil,
+		step.Kustomize != nil,
+		step.JSONPatch != nil,
+		step.YQ != nil,
+		step.HelmTemplate != nil,
+	} {
+		if mutation {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return "", ocmmetav1.Identity{}, errors.New("a step must define exactly one mutation")
+	}
+
+	switch {
+	case step.ConfigRef != nil:
+		status := obj.GetStatus()
+		matched, skipped := len(status.MatchedFiles), len(status.SkippedRules)
+
+		sourceDir, snapshotID, err := m.mutateConfigRef(ctx, obj, mutationSpec, step.ConfigRef, sourceData)
+		if err != nil {
+			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to apply config ref: %w", err)
+		}
+
+		// the rules are named after their config data, which several steps may share
+		if len(mutationSpec.Steps) > 0 {
+			qualifyRules(status, fmt.Sprintf("steps[%d].", index), matched, skipped)
+		}
+
+		return sourceDir, snapshotID, nil
+	case step.PatchStrategicMerge != nil:
+		sourceDir, snapshotID, err := m.mutatePatchStrategicMerge(ctx, obj, step.PatchStrategicMerge, sourceData)
 		if err != nil {
 			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to apply patch strategic merge strategy: %w", err)
 		}
+
+		return sourceDir, snapshotID, nil
+	case step.Kustomize != nil:
+		sourceDir, snapshotID, err := m.mutateKustomize(ctx, obj, step.Kustomize, sourceData)
+		if err != nil {
+			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to apply kustomize: %w", err)
+		}
+
+		return sourceDir, snapshotID, nil
+	case step.JSONPatch != nil:
+		sourceDir, snapshotID, err := m.mutateJSONPatch(obj, fmt.Sprintf("steps[%d].jsonPatch", index), step.JSONPatch, sourceData)
+		if err != nil {
+			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to apply json patch: %w", err)
+		}
+
+		return sourceDir, snapshotID, nil
+	case step.YQ != nil:
+		sourceDir, snapshotID, err := m.mutateYQ(obj, fmt.Sprintf("steps[%d].yq", index), step.YQ, sourceData)
+		if err != nil {
+			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to apply yq expression: %w", err)
+		}
+
+		return sourceDir, snapshotID, nil
+	default:
+		sourceDir, snapshotID, err := m.mutateHelmTemplate(ctx, obj, mutationSpec, step.HelmTemplate, sourceData)
+		if err != nil {
+			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to render helm chart: %w", err)
+		}
+
+		return sourceDir, snapshotID, nil
 	}
+}
 
-	return sourceDir, snapshotID, nil
+// compositeIdentity combines the identities of the steps. A single step keeps its identity, otherwise
+// the identity of the first step is extended with a digest of the identities of all steps in order, so
+// a change to the input of any step results in a different snapshot.
+func compositeIdentity(identities []ocmmetav1.Identity) (ocmmetav1.Identity, error) {
+	switch len(identities) {
+	case 0:
+		return nil, nil
+	case 1:
+		return identities[0], nil
+	}
+
+	hash := sha256.New()
+	for _, identity := range identities {
+		// maps are marshaled with sorted keys, so the digest is stable
+		raw, err := json.Marshal(identity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal step identity: %w", err)
+		}
+
+		hash.Write(raw)
+		hash.Write([]byte{'\n'})
+	}
+
+	result := ocmmetav1.Identity{}
+	for k, v := range identities[0] {
+		result[k] = v
+	}
+
+	result[v1alpha1.MutationStepsDigestKey] = hex.EncodeToString(hash.Sum(nil))
+
+	return result, nil
 }
 
 func (m *MutationReconcileLooper) configure(
 	ctx context.Context,
-	data, configObj []byte,
+	obj v1alpha1.MutationObject,
+	data []byte,
+	config *configdata.ConfigData,
 	mutationSpec *v1alpha1.MutationSpec,
-	namespace, name string,
+	configRef *v1alpha1.ObjectReference,
 ) (string, error) {
-	configValues, err := m.getValues(ctx, mutationSpec, namespace, name)
+	mergedValues, err := m.getValues(ctx, mutationSpec, obj.GetNamespace(), obj.GetName())
 	if err != nil {
 		return "", fmt.Errorf("failed to get values: %w", err)
 	}
 
+	configValues, err := setEffectiveValues(ctx, obj, mergedValues)
+	if err != nil {
+		return "", err
+	}
+
 	log := log.FromContext(ctx)
 
 	virtualFS, err := osfs.NewTempFileSystem()
@@ -169,16 +372,30 @@ func (m *MutationReconcileLooper) configure(
 		return "", fmt.Errorf("extract tar error: %w", err)
 	}
 
-	rules, err := m.createSubstitutionRulesForConfigurationValues(configObj, configValues)
+	conditions := m.newRuleConditions(ctx, mutationSpec, configRef, mergedValues)
+
+	profile, err := m.selectProfile(ctx, obj)
+	if err != nil {
+		return "", err
+	}
+
+	rules, matches, err := m.createSubstitutionRulesForConfigurationValues(config, configValues, sourceDir, profile, conditions)
 	if err != nil {
 		return "", err
 	}
 
+	if profile.applied != "" {
+		obj.GetStatus().Profile = profile.applied
+	}
+
+	obj.GetStatus().MatchedFiles = append(obj.GetStatus().MatchedFiles, matches...)
+	obj.GetStatus().SkippedRules = append(obj.GetStatus().SkippedRules, conditions.skippedRules()...)
+
 	if len(rules) == 0 {
 		log.Info("no rules generated from the available config data; the generate snapshot will have no modifications")
 	}
 
-	if err := localize.Substitute(rules, virtualFS); err != nil {
+	if err := substitute.Apply(sourceDir, rules); err != nil {
 		return "", fmt.Errorf("localization substitution failed: %w", err)
 	}
 
@@ -187,12 +404,14 @@ func (m *MutationReconcileLooper) configure(
 
 func (m *MutationReconcileLooper) localize(
 	ctx context.Context,
-	mutationSpec *v1alpha1.MutationSpec,
-	data, configObj []byte,
+	obj v1alpha1.MutationObject,
+	configRef *v1alpha1.ObjectReference,
+	data []byte,
+	config *configdata.ConfigData,
 ) (string, error) {
 	logger := log.FromContext(ctx)
 
-	cv, err := m.getComponentVersion(ctx, mutationSpec.ConfigRef)
+	cv, err := m.getComponentVersion(ctx, configRef)
 	if err != nil {
 		return "", fmt.Errorf("failed to get component version: %w", err)
 	}
@@ -202,8 +421,8 @@ func (m *MutationReconcileLooper) localize(
 	}
 
 	var refPath []ocmmetav1.Identity
-	if mutationSpec.ConfigRef.ResourceRef != nil {
-		refPath = mutationSpec.ConfigRef.ResourceRef.ReferencePath
+	if configRef.ResourceRef != nil {
+		refPath = configRef.ResourceRef.ReferencePath
 	}
 
 	virtualFS, err := osfs.NewTempFileSystem()
@@ -226,19 +445,31 @@ func (m *MutationReconcileLooper) localize(
 		return "", fmt.Errorf("extract tar error: %w", err)
 	}
 
-	rules, err := m.createSubstitutionRulesForLocalization(ctx, cv, configObj, refPath)
+	conditions := m.newRuleConditions(ctx, obj.GetSpec(), configRef, nil)
+
+	rules, matches, images, err := m.createSubstitutionRulesForLocalization(ctx, obj, cv, config, refPath, sourceDir, conditions)
 	if err != nil {
 		return "", fmt.Errorf("failed to create substitution rules for localization: %w", err)
 	}
 
+	obj.GetStatus().MatchedFiles = append(obj.GetStatus().MatchedFiles, matches...)
+	obj.GetStatus().Images = append(obj.GetStatus().Images, images...)
+	obj.GetStatus().SkippedRules = append(obj.GetStatus().SkippedRules, conditions.skippedRules()...)
+
 	if len(rules) == 0 {
 		logger.Info("no rules generated from the available config data; the generate snapshot will have no modifications")
 	}
 
-	if err := localize.Substitute(rules, virtualFS); err != nil {
+	if err := substitute.Apply(sourceDir, rules); err != nil {
 		return "", fmt.Errorf("localization substitution failed: %w", err)
 	}
 
+	if loc, ok := obj.(*v1alpha1.Localization); ok && loc.Spec.PullSecret != nil {
+		if err := m.applyPullSecret(ctx, loc, cv, sourceDir); err != nil {
+			return "", fmt.Errorf("failed to apply pull secret: %w", err)
+		}
+	}
+
 	return sourceDir, nil
 }
 
@@ -345,6 +576,18 @@ func (m *MutationReconcileLooper) getSnapshotBytes(ctx context.Context, snapshot
 		return nil, fmt.Errorf("failed to fetch data: %w", err)
 	}
 
+	// the content of the reconciled digest is encrypted as recorded with it, the spec may be ahead
+	if snapshot.Status.LastReconciledEncryption != nil {
+		defer reader.Close()
+
+		plaintext, err := ocmsnapshot.Decrypt(ctx, m.Client, snapshot, reader)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = io.NopCloser(bytes.NewReader(plaintext))
+	}
+
 	if uncompress {
 		uncompressed, _, err := compression.AutoDecompress(reader)
 		if err != nil {
@@ -361,106 +604,249 @@ func (m *MutationReconcileLooper) getSnapshotBytes(ctx context.Context, snapshot
 
 func (m *MutationReconcileLooper) createSubstitutionRulesForLocalization(
 	ctx context.Context,
+	obj v1alpha1.MutationObject,
 	cv *v1alpha1.ComponentVersion,
-	data []byte,
+	config *configdata.ConfigData,
 	refPath []ocmmetav1.Identity,
-) (localize.Substitutions, error) {
-	config := &configdata.ConfigData{}
-	if err := ocmruntime.DefaultYAMLEncoding.Unmarshal(data, config); err != nil {
-		return nil,
-			fmt.Errorf("failed to unmarshal content: %w", err)
-	}
-
+	sourceDir string,
+	conditions *ruleConditions,
+) (substitute.Substitutions, []v1alpha1.RuleMatch, []v1alpha1.ImageMatch, error) {
 	octx, err := m.OCMClient.CreateAuthenticatedOCMContext(ctx, cv)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create authenticated client: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to create authenticated client: %w", err)
 	}
 
 	compvers, err := m.OCMClient.GetComponentVersion(ctx, octx, cv.GetRepositoryURL(), cv.Spec.Component, cv.Status.ReconciledVersion)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get component version: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get component version: %w", err)
 	}
 	defer compvers.Close()
 
-	var localizations localize.Substitutions
-	for _, l := range config.Localization {
+	var (
+		localizations substitute.Substitutions
+		matches       []v1alpha1.RuleMatch
+		images        []v1alpha1.ImageMatch
+		scope         map[string]any
+	)
+
+	opts := m.locationOptions(obj.GetSpec(), cv)
+
+	// images are localized first, so rules can override them
+	if config.AutoLocalization != nil {
+		if images, err = m.autoLocalize(ctx, octx, config.AutoLocalization, config.PreferDigest, sourceDir, compvers, opts, &localizations); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to localize images: %w", err)
+		}
+	}
+
+	for i, l := range config.Localization {
+		rule := fmt.Sprintf("localization[%d]", i)
+
+		ok, err := conditions.applies(rule, l.When)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		files, err := matchFiles(sourceDir, l.File, l.Document)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to match files of localization rule %d: %w", i, err)
+		}
+
+		matches = append(matches, v1alpha1.RuleMatch{
+			Rule:    rule,
+			Pattern: l.File,
+			Files:   files,
+		})
+
+		if len(files) == 0 {
+			continue
+		}
+
 		if l.Mapping != nil {
-			res, err := m.compileMapping(ctx, cv, l.Mapping.Transform)
+			// the scope is shared by the mappings, resolving the access of every resource is only done once
+			if scope == nil {
+				if scope, err = mappingScope(obj, compvers, opts); err != nil {
+					return nil, nil, nil, fmt.Errorf("failed to create scope of mapping: %w", err)
+				}
+			}
+
+			outputs, err := m.compileMapping(ctx, cv, scope, l.Mapping)
 			if err != nil {
-				return nil, fmt.Errorf("failed to compile mapping: %w", err)
+				return nil, nil, nil, fmt.Errorf("failed to compile mapping: %w", err)
 			}
 
-			if err := localizations.Add("custom", l.File, l.Mapping.Path, res); err != nil {
-				return nil, fmt.Errorf("failed to add identifier: %w", err)
+			for _, output := range outputs {
+				for _, file := range files {
+					if err := localizations.Add("custom", localizationTarget(l, file), output.path, output.value); err != nil {
+						return nil, nil, nil, fmt.Errorf("failed to add identifier: %w", err)
+					}
+				}
 			}
 
 			continue
 		}
 
-		if err := m.performLocalization(octx, l, &localizations, refPath, compvers); err != nil {
-			return nil, fmt.Errorf("failed to perform localization: %w", err)
+		if err := m.performLocalization(ctx, octx, l, files, &localizations, refPath, compvers, opts, config.PreferDigest); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to perform localization: %w", err)
 		}
 	}
 
-	return localizations, nil
+	return localizations, matches, images, nil
+}
+
+// locationOptions returns the options the locations of the resources of the component version are resolved
+// with. The mirrors of the spec come first, so they're used if the controller has a mirror of the same source.
+func (m *MutationReconcileLooper) locationOptions(spec *v1alpha1.MutationSpec, cv *v1alpha1.ComponentVersion) access.Options {
+	mirrors := make([]access.Mirror, 0, len(spec.RegistryMirrors)+len(m.RegistryMirrors))
+	for _, mirror := range spec.RegistryMirrors {
+		mirrors = append(mirrors, access.Mirror{Source: mirror.Source, Target: mirror.Mirror})
+	}
+
+	return access.Options{RepositoryURL: cv.GetRepositoryURL(), Mirrors: append(mirrors, m.RegistryMirrors...)}
+}
+
+func localizationTarget(l configdata.LocalizationRule, file string) substitute.Target {
+	return substitute.Target{File: file, Document: l.Document, Format: l.Format}
+}
+
+// matchFiles expands the file pattern of a rule. A plain file name without a document selector must
+// match an existing file.
+func matchFiles(root, pattern string, selector *configdata.DocumentSelector) ([]string, error) {
+	files, err := substitute.Files(root, pattern, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 && selector == nil && !substitute.IsPattern(pattern) {
+		return nil, fmt.Errorf("file %s not found", pattern)
+	}
+
+	return files, nil
 }
 
 func (m *MutationReconcileLooper) performLocalization(
+	ctx context.Context,
 	octx ocmcore.Context,
 	l configdata.LocalizationRule,
-	localizations *localize.Substitutions,
+	files []string,
+	localizations *substitute.Substitutions,
 	refPath []ocmmetav1.Identity,
 	compvers ocmcore.ComponentVersionAccess,
+	opts access.Options,
+	preferDigest bool,
 ) error {
-	pRef, err := resolveReference(l, refPath, compvers, octx)
+	loc, err := resolveLocation(l, refPath, compvers, opts)
 	if err != nil {
 		return err
 	}
 
-	if l.Registry != "" {
-		if err := localizations.Add("registry", l.File, l.Registry, pRef.Context().Registry.Name()); err != nil {
-			return fmt.Errorf("failed to add registry: %w", err)
+	needsDigest := l.Digest != "" || l.ImageWithDigest != "" || (preferDigest && l.Image != "")
+	if needsDigest && loc.Digest == "" && loc.Image != "" {
+		// the access doesn't pin a digest, ask the registry which manifest the reference currently points to
+		if loc.Digest, err = m.OCMClient.GetImageDigest(ctx, octx, loc.Image); err != nil {
+			return fmt.Errorf("failed to get digest of resource %s: %w", l.Resource.Name, err)
 		}
 	}
 
-	if l.Repository != "" {
-		if err := localizations.Add("repository", l.File, l.Repository, pRef.Context().RepositoryStr()); err != nil {
-			return fmt.Errorf("failed to add repository: %w", err)
-		}
+	image := loc.Image
+	if preferDigest {
+		image = loc.ImageWithDigest()
 	}
 
-	if l.FullyQualifiedRepository != "" {
-		ctxt := pRef.Context()
-		if err := localizations.Add("fullyQualifiedRepository", l.File, l.FullyQualifiedRepository,
-			ctxt.Registry.Name()+"/"+ctxt.RepositoryStr()); err != nil {
-			return fmt.Errorf("failed to add repository: %w", err)
-		}
+	fields := []struct {
+		name, path, value string
+	}{
+		{name: "registry", path: l.Registry, value: loc.Registry},
+		{name: "repository", path: l.Repository, value: loc.Repository},
+		{name: "fullyQualifiedRepository", path: l.FullyQualifiedRepository, value: loc.FullyQualifiedRepository()},
+		{name: "image", path: l.Image, value: image},
+		{name: "tag", path: l.Tag, value: loc.Tag},
+		{name: "digest", path: l.Digest, value: loc.Digest},
+		{name: "imageWithDigest", path: l.ImageWithDigest, value: loc.ImageWithDigest()},
+		{name: "url", path: l.URL, value: loc.URL},
 	}
 
-	if l.Image != "" {
-		if err := localizations.Add("image", l.File, l.Image, pRef.Name()); err != nil {
-			return fmt.Errorf("failed to add image ref name: %w", err)
+	for _, f := range fields {
+		if f.path == "" {
+			continue
 		}
-	}
 
-	if l.Tag != "" {
-		if err := localizations.Add("tag", l.File, l.Tag, pRef.Identifier()); err != nil {
-			return fmt.Errorf("failed to add identifier: %w", err)
+		if f.value == "" {
+			return fmt.Errorf("access type %s of resource %s does not provide a %s", loc.AccessType, l.Resource.Name, f.name)
+		}
+
+		for _, file := range files {
+			if err := localizations.Add(f.name, localizationTarget(l, file), f.path, f.value); err != nil {
+				return fmt.Errorf("failed to add %s: %w", f.name, err)
+			}
 		}
 	}
 
 	return nil
 }
 
-func resolveReference(
-	l configdata.LocalizationRule,
+// resolveResource looks up a resource by its full identity. The version is only part of the identity of a
+// resource if name and extra identity aren't unique, therefore the resource is looked up both with and
+// without the version.
+func resolveResource(
+	item configdata.ResourceItem,
 	refPath []ocmmetav1.Identity,
 	compvers ocmcore.ComponentVersionAccess,
-	octx ocmcore.Context,
-) (name.Reference, error) {
-	resourceRef := ocmmetav1.NewNestedResourceRef(ocmmetav1.NewIdentity(l.Resource.Name), refPath)
+) (ocmcore.ResourceAccess, error) {
+	identity := ocmmetav1.NewIdentity(item.Name)
+	for k, v := range item.ExtraIdentity {
+		identity[k] = v
+	}
+
+	if item.Version != "" {
+		versioned := identity.Copy()
+		versioned[ocmmetav1.SystemIdentityVersion] = item.Version
+
+		resourceRef := ocmmetav1.NewNestedResourceRef(versioned, refPath)
+
+		resource, _, err := resourcerefs.ResolveResourceReference(compvers, resourceRef, compvers.Repository())
+		if err == nil {
+			return resource, nil
+		}
+
+		if !ocmerrors.IsErrNotFound(err) {
+			return nil, fmt.Errorf("failed to resolve resource %s with version %s: %w", item.Name, item.Version, err)
+		}
+	}
+
+	resourceRef := ocmmetav1.NewNestedResourceRef(identity, refPath)
 
 	resource, _, err := resourcerefs.ResolveResourceReference(compvers, resourceRef, compvers.Repository())
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Version != "" && resource.Meta().Version != item.Version {
+		return nil, fmt.Errorf("resource %s has version %s, but %s was requested", item.Name, resource.Meta().Version, item.Version)
+	}
+
+	return resource, nil
+}
+
+// resolveLocation determines where the resource of a localization rule is located using the
+// access specification of the resource.
+func resolveLocation(
+	l configdata.LocalizationRule,
+	refPath []ocmmetav1.Identity,
+	compvers ocmcore.ComponentVersionAccess,
+	opts access.Options,
+) (*access.Location, error) {
+	if len(l.Resource.ReferencePath) > 0 {
+		refPath = make([]ocmmetav1.Identity, 0, len(l.Resource.ReferencePath))
+		for _, id := range l.Resource.ReferencePath {
+			refPath = append(refPath, id)
+		}
+	}
+
+	resource, err := resolveResource(l.Resource, refPath, compvers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch resource from component version: %w", err)
 	}
@@ -470,78 +856,190 @@ func resolveReference(
 		return nil, err
 	}
 
-	var (
-		ref    string
-		refErr error
-	)
-
-	for ref == "" && refErr == nil {
-		switch x := accSpec.(type) {
-		case *ociartifact.AccessSpec:
-			ref = x.ImageReference
-		case *ociblob.AccessSpec:
-			ref = fmt.Sprintf("%s@%s", x.Reference, x.Digest)
-		case *localblob.AccessSpec:
-			if x.GlobalAccess == nil {
-				refErr = errors.New("cannot determine image digest")
-			} else {
-				accSpec, refErr = octx.AccessSpecForSpec(x.GlobalAccess)
-			}
-		default:
-			refErr = errors.New("cannot determine access spec type")
-		}
+	spec, err := access.ToMap(accSpec)
+	if err != nil {
+		return nil, err
 	}
 
-	if refErr != nil {
-		return nil, fmt.Errorf("failed to parse access reference: %w", refErr)
-	}
+	opts.Version = resource.Meta().Version
 
-	pRef, err := name.ParseReference(ref)
+	loc, err := access.NewRegistry().Resolve(spec, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse access reference: %w", err)
 	}
 
-	return pRef, nil
+	return loc, nil
 }
 
 func (m *MutationReconcileLooper) createSubstitutionRulesForConfigurationValues(
-	data []byte,
-	values *apiextensionsv1.JSON,
-) (localize.Substitutions, error) {
-	config := &configdata.ConfigData{}
-	if err := ocmruntime.DefaultYAMLEncoding.Unmarshal(data, config); err != nil {
-		return nil,
-			fmt.Errorf("failed to unmarshal content: %w", err)
+	config *configdata.ConfigData,
+	configValues *apiextensionsv1.JSON,
+	sourceDir string,
+	profile *configProfile,
+	conditions *ruleConditions,
+) (substitute.Substitutions, []v1alpha1.RuleMatch, error) {
+	spec, configRules, err := profile.apply(config.Configuration)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var rules localize.Substitutions
-	for i, l := range config.Configuration.Rules {
+	skipped := make(map[int]bool)
+	for i, l := range configRules {
+		ok, err := conditions.applies(l.name, l.When)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !ok {
+			skipped[i] = true
+
+			continue
+		}
+
 		if err := rules.Add(fmt.Sprintf("subst-%d", i), l.File, l.Path, l.Value); err != nil {
-			return nil, fmt.Errorf("failed to add rule: %w", err)
+			return nil, nil, fmt.Errorf("failed to add rule: %w", err)
+		}
+	}
+
+	// the overlay is evaluated by spiff like a rule, but merged into the values file of the chart
+	if spec.HelmValues != nil {
+		if err := rules.Add(helmValuesSubstitution, "", "", spec.HelmValues); err != nil {
+			return nil, nil, fmt.Errorf("failed to add helm values: %w", err)
+		}
+	}
+
+	configSubstitutions, err := m.evaluateSubstitutions(spec, rules, configValues.Raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configurator error: %w", err)
+	}
+
+	var helmValues []byte
+	for i, subst := range configSubstitutions {
+		if subst.ValueMapping.Name == helmValuesSubstitution {
+			helmValues = subst.ValueMapping.Value
+			configSubstitutions = slices.Delete(configSubstitutions, i, i+1)
+
+			break
 		}
 	}
 
-	defaults, err := json.Marshal(config.Configuration.Defaults)
+	substitutions, matches, err := expandConfigurationRules(sourceDir, configRules, skipped, configSubstitutions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal configuration defaults: %w", err) //nolint:staticcheck // it's fine
+		return nil, nil, err
 	}
 
-	schema, err := json.Marshal(config.Configuration.Schema) //nolint:staticcheck // it's fine
+	if helmValues != nil {
+		match, err := mergeHelmValues(sourceDir, helmValues)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		matches = append(matches, match)
+	}
+
+	return substitutions, matches, nil
+}
+
+// mergeHelmValues merges the evaluated helm values overlay into the values file of the chart. The chart keeps
+// its name and version, the snapshot is tagged with the version, see helmChartVersion.
+func mergeHelmValues(sourceDir string, overlay []byte) (v1alpha1.RuleMatch, error) {
+	file, err := helm.ValuesFile(sourceDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal configuration schema: %w", err)
+		return v1alpha1.RuleMatch{}, fmt.Errorf("helm values require a helm chart resource: %w", err)
 	}
 
-	configSubstitutions, err := m.generateSubstitutions(rules, defaults, values.Raw, schema)
+	path, err := securejoin.SecureJoin(sourceDir, file)
 	if err != nil {
-		return nil, fmt.Errorf("configurator error: %w", err)
+		return v1alpha1.RuleMatch{}, err
+	}
+
+	// charts without default values have no values file
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(path, nil, FSOwnerReadWrite); err != nil {
+			return v1alpha1.RuleMatch{}, fmt.Errorf("failed to create values file: %w", err)
+		}
+	}
+
+	if err := substitute.Merge(sourceDir, file, overlay); err != nil {
+		return v1alpha1.RuleMatch{}, fmt.Errorf("failed to merge helm values: %w", err)
+	}
+
+	return v1alpha1.RuleMatch{
+		Rule:    helmValuesRule,
+		Pattern: file,
+		Files:   []string{file},
+	}, nil
+}
+
+// helmChartVersion returns the version of the chart if helm values were merged into it, so the snapshot is
+// tagged with the version of the chart like in a helm repository.
+func helmChartVersion(sourceDir string, matches []v1alpha1.RuleMatch) (string, error) {
+	if !slices.ContainsFunc(matches, func(match v1alpha1.RuleMatch) bool { return match.Rule == helmValuesRule }) {
+		return "", nil
+	}
+
+	version, err := helm.ChartVersion(sourceDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get chart version: %w", err)
+	}
+
+	return version, nil
+}
+
+// expandConfigurationRules creates a substitution for every file matched by a configuration rule which
+// wasn't skipped. The substitutions produced by spiff are named after the index of the rule they were
+// created from.
+func expandConfigurationRules(
+	sourceDir string,
+	rules []namedRule,
+	skipped map[int]bool,
+	configSubstitutions localize.Substitutions,
+) (substitute.Substitutions, []v1alpha1.RuleMatch, error) {
+	matches := make([]v1alpha1.RuleMatch, 0, len(rules))
+	index := make(map[string]int, len(rules))
+	matchedFiles := make(map[int][]string, len(rules))
+
+	for i, rule := range rules {
+		if skipped[i] {
+			continue
+		}
+
+		files, err := matchFiles(sourceDir, rule.File, rule.Document)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to match files of configuration rule %s: %w", rule.name, err)
+		}
+
+		matches = append(matches, v1alpha1.RuleMatch{
+			Rule:    rule.name,
+			Pattern: rule.File,
+			Files:   files,
+		})
+		index[fmt.Sprintf("subst-%d", i)] = i
+		matchedFiles[i] = files
+	}
+
+	var result substitute.Substitutions
+	for _, s := range configSubstitutions {
+		i, ok := index[s.ValueMapping.Name]
+		if !ok {
+			return nil, nil, fmt.Errorf("substitution %s does not belong to a configuration rule", s.ValueMapping.Name)
+		}
+
+		for _, file := range matchedFiles[i] {
+			target := substitute.Target{File: file, Document: rules[i].Document, Format: rules[i].Format}
+			if err := result.Add(s.ValueMapping.Name, target, s.ValueMapping.ValuePath, s.ValueMapping.Value); err != nil {
+				return nil, nil, fmt.Errorf("failed to add rule: %w", err)
+			}
+		}
 	}
 
-	return configSubstitutions, nil
+	return result, matches, nil
 }
 
 func (m *MutationReconcileLooper) generateSubstitutions(
 	subst []localize.Substitution,
-	defaults, configValues, schema []byte,
+	defaults, configValues []byte,
 ) (localize.Substitutions, error) {
 	var err error
 	var spiffTemplateDoc *spiffTemplateDoc
@@ -559,12 +1057,6 @@ func (m *MutationReconcileLooper) generateSubstitutions(
 		return nil, err
 	}
 
-	if len(schema) > 0 {
-		if err := spiff.ValidateByScheme(configValues, schema); err != nil {
-			return nil, fmt.Errorf("validation failed: %w", err)
-		}
-	}
-
 	config, err := spiff.CascadeWith(spiff.TemplateData(ocmAdjustmentsTemplateKey, spiffTemplateBytes), spiff.Mode(spiffing.MODE_PRIVATE))
 	if err != nil {
 		return nil, fmt.Errorf("error while doing cascade with: %w", err)
@@ -581,7 +1073,12 @@ func (m *MutationReconcileLooper) generateSubstitutions(
 	return result.Adjustments, nil
 }
 
-func (m *MutationReconcileLooper) compileMapping(ctx context.Context, cv *v1alpha1.ComponentVersion, mapping string) (json.RawMessage, error) {
+func (m *MutationReconcileLooper) compileMapping(
+	ctx context.Context,
+	cv *v1alpha1.ComponentVersion,
+	scope map[string]any,
+	mapping *configdata.Mapping,
+) ([]mappingOutput, error) {
 	cueCtx := cuecontext.New()
 	cd, err := component.GetComponentDescriptor(ctx, m.Client, nil, cv.Status.ComponentDescriptor)
 	if err != nil {
@@ -601,22 +1098,14 @@ func (m *MutationReconcileLooper) compileMapping(ctx context.Context, cv *v1alph
 		return nil, err
 	}
 
-	// populate the mapping
-	v := cueCtx.CompileString(mapping, cue.Scope(root))
-
-	// resolve the output
-	res, err := v.LookupPath(cue.ParsePath("out")).Bytes()
-	if err != nil {
-		return nil, err
+	for name, value := range scope {
+		root = root.FillPath(cue.MakePath(cue.Str(name)), cueCtx.Encode(value))
 	}
 
-	// format the result
-	var out json.RawMessage
-	if err := json.Unmarshal(res, &out); err != nil {
-		return nil, err
-	}
+	// populate the mapping
+	v := cueCtx.CompileString(mapping.Transform, cue.Scope(root))
 
-	return out, nil
+	return mappingOutputs(v, mapping)
 }
 
 func (m *MutationReconcileLooper) populateReferences(ctx context.Context, src cue.Value, namespace string) (cue.Value, error) {
@@ -695,15 +1184,8 @@ func getStructFieldValue(v *cue.Struct, field string) (string, error) {
 }
 
 func (m *MutationReconcileLooper) getSource(ctx context.Context, ref meta.NamespacedObjectKindReference) (sourcev1.Source, error) {
-	var obj client.Object
-	switch ref.Kind {
-	case sourcev1.GitRepositoryKind:
-		obj = &sourcev1.GitRepository{}
-	// case sourcev1.BucketKind:
-	//     obj = &sourcev1.Bucket{}
-	// case sourcev1.OCIRepositoryKind:
-	//     obj = &sourcev1.OCIRepository{}
-	default:
+	obj, ok := newFluxSource(ref.Kind)
+	if !ok {
 		return nil, fmt.Errorf("source `%s` kind '%s' not supported", ref.Name, ref.Kind)
 	}
 
@@ -717,12 +1199,57 @@ func (m *MutationReconcileLooper) getSource(ctx context.Context, ref meta.Namesp
 		return nil, fmt.Errorf("unable to get source '%s': %w", key, err)
 	}
 
-	source, ok := obj.(sourcev1.Source)
-	if !ok {
-		return nil, fmt.Errorf("object is not a source object: %+v", obj)
+	return obj, nil
+}
+
+// fetchSourceArtifact downloads and extracts the artifact of a Flux source into dir.
+func (m *MutationReconcileLooper) fetchSourceArtifact(
+	ctx context.Context,
+	ref meta.NamespacedObjectKindReference,
+	dir string,
+) (*meta.Artifact, error) {
+	source, err := m.getSource(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := source.GetArtifact()
+	if artifact == nil {
+		return nil, fmt.Errorf("could not get artifact from source: %s", ref.Name)
 	}
 
-	return source, nil
+	tarSize := tar.UnlimitedUntarSize
+	const retries = 10
+	fetcher := fetch.NewArchiveFetcher(retries, tarSize, tarSize, "")
+	if err := fetcher.Fetch(artifact.URL, artifact.Digest, dir); err != nil {
+		return nil, fmt.Errorf("could not fetch artifact from source: %w", err)
+	}
+
+	return artifact, nil
+}
+
+// fetchFileFromSource returns the content of a file in the artifact of a Flux source.
+func (m *MutationReconcileLooper) fetchFileFromSource(ctx context.Context, obj *v1alpha1.ObjectReference) ([]byte, error) {
+	if obj.Path == "" {
+		return nil, fmt.Errorf("path must be set to read data from %s %s", obj.Kind, obj.Name)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "source-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if _, err := m.fetchSourceArtifact(ctx, obj.NamespacedObjectKindReference, tmpDir); err != nil {
+		return nil, err
+	}
+
+	path, err := securejoin.SecureJoin(tmpDir, obj.Path)
+	if err != nil {
+		return nil, fmt.Errorf("could not construct file path: %w", err)
+	}
+
+	return os.ReadFile(path)
 }
 
 func (m *MutationReconcileLooper) getData(ctx context.Context, obj *v1alpha1.ObjectReference) ([]byte, error) {
@@ -731,12 +1258,17 @@ func (m *MutationReconcileLooper) getData(ctx context.Context, obj *v1alpha1.Obj
 		err  error
 	)
 
-	switch obj.Kind {
-	case v1alpha1.ComponentVersionKind:
+	switch {
+	case obj.Kind == v1alpha1.ComponentVersionKind:
 		if data, err = m.fetchDataFromComponentVersion(ctx, obj); err != nil {
 			return nil,
 				fmt.Errorf("failed to fetch resource data from resource ref: %w", err)
 		}
+	case isFluxSource(obj.Kind):
+		if data, err = m.fetchFileFromSource(ctx, obj); err != nil {
+			return nil,
+				fmt.Errorf("failed to fetch data from source: %w", err)
+		}
 	default:
 		if data, _, err = m.fetchDataFromObjectReference(ctx, obj, true); err != nil {
 			return nil,
@@ -758,8 +1290,8 @@ func (m *MutationReconcileLooper) getIdentity(ctx context.Context, obj *v1alpha1
 		Namespace: obj.Namespace,
 	}
 
-	switch obj.Kind {
-	case v1alpha1.ComponentVersionKind:
+	switch {
+	case obj.Kind == v1alpha1.ComponentVersionKind:
 		cv := &v1alpha1.ComponentVersion{}
 		if err := m.Client.Get(ctx, key, cv); err != nil {
 			return nil, err
@@ -773,25 +1305,24 @@ func (m *MutationReconcileLooper) getIdentity(ctx context.Context, obj *v1alpha1
 		if err := m.configureResourceVersion(ctx, obj, id, cv); err != nil {
 			return nil, err
 		}
-	default:
-		// if kind is not ComponentVersion, then fetch resource using dynamic client
-		// and get the snapshot name from the resource
-		gvr := obj.GetGVR()
-		src, err := m.DynamicClient.Resource(gvr).Namespace(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case isFluxSource(obj.Kind):
+		source, err := m.getSource(ctx, obj.NamespacedObjectKindReference)
 		if err != nil {
 			return nil, err
 		}
 
-		snapshotName, ok, err := unstructured.NestedString(src.Object, "status", "snapshotName")
-		if err != nil {
-			return nil, fmt.Errorf("failed get the get snapshot: %w", err)
-		}
-		if !ok {
-			return nil, errors.New("snapshot name not found in status")
+		if source.GetArtifact() == nil {
+			return nil, fmt.Errorf("could not get artifact from source: %s", obj.Name)
 		}
 
-		snapshot := &v1alpha1.Snapshot{}
-		if err := m.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: snapshotName}, snapshot); err != nil {
+		id = ocmmetav1.Identity{
+			v1alpha1.SourceNameKey:             obj.Name,
+			v1alpha1.SourceNamespaceKey:        obj.Namespace,
+			v1alpha1.SourceArtifactChecksumKey: source.GetArtifact().Digest,
+		}
+	default:
+		snapshot, err := m.getSnapshot(ctx, obj)
+		if err != nil {
 			return nil, err
 		}
 
@@ -801,6 +1332,61 @@ func (m *MutationReconcileLooper) getIdentity(ctx context.Context, obj *v1alpha1
 	return id, err
 }
 
+// getArtifactMetadata returns the metadata describing the origin of the referenced data. Snapshots carry
+// the metadata of the resource they were created from, resources of component versions are looked up
+// in the component descriptor.
+func (m *MutationReconcileLooper) getArtifactMetadata(ctx context.Context, obj *v1alpha1.ObjectReference) (*v1alpha1.ArtifactMetadata, error) {
+	if obj.Kind != v1alpha1.ComponentVersionKind {
+		snapshot, err := m.getSnapshot(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		return snapshot.Spec.Artifact.DeepCopy(), nil
+	}
+
+	if obj.ResourceRef == nil {
+		return nil, nil
+	}
+
+	cv, err := m.getComponentVersion(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	cd, err := component.GetComponentDescriptor(ctx, m.Client, obj.ResourceRef.ReferencePath, cv.Status.ComponentDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get component descriptor: %w", err)
+	}
+
+	return component.GetArtifactMetadata(cd, obj.ResourceRef), nil
+}
+
+// getSnapshot fetches the referenced object using the dynamic client and returns the snapshot
+// named in its status.
+func (m *MutationReconcileLooper) getSnapshot(ctx context.Context, obj *v1alpha1.ObjectReference) (*v1alpha1.Snapshot, error) {
+	gvr := obj.GetGVR()
+	src, err := m.DynamicClient.Resource(gvr).Namespace(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotName, ok, err := unstructured.NestedString(src.Object, "status", "snapshotName")
+	if err != nil {
+		return nil, fmt.Errorf("failed get the get snapshot: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("snapshot name not found in status")
+	}
+
+	snapshot := &v1alpha1.Snapshot{}
+	if err := m.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: snapshotName}, snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
 // 2024-07-10 d :
 // Partial solution for #68
 // Helm chart in the private OCI repository must have a tag that matches the version
@@ -866,88 +1452,140 @@ func (m *MutationReconcileLooper) getComponentVersion(ctx context.Context, obj *
 	return cv, nil
 }
 
-// getValues returns values that can be used for the configuration
-// currently it only possible to use inline values OR values from an external source.
+// getValues returns the values used for the configuration. The values of all sources are merged in
+// order and inline values are deep merged on top of them.
 func (m *MutationReconcileLooper) getValues(
 	ctx context.Context, obj *v1alpha1.MutationSpec, namespace, name string,
-) (*apiextensionsv1.JSON, error) {
+) (map[string]any, error) {
+	sources := obj.GetValuesSources()
+	layers := make([]values.Layer, 0, len(sources)+1)
+
+	for i, source := range sources {
+		data, err := m.getValuesFromSource(ctx, obj, source, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get values from source %d: %w", i, err)
+		}
+
+		layers = append(layers, values.Layer{Values: data, Strategy: source.MergeStrategy, MergeKey: source.MergeKey})
+	}
+
 	if obj.Values != nil {
-		return obj.Values, nil
+		var data map[string]any
+		if err := json.Unmarshal(obj.Values.Raw, &data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal inline values: %w", err)
+		}
+
+		layers = append(layers, values.Layer{Values: data})
+	}
+
+	if len(layers) == 0 {
+		return nil, errors.New("no values found")
 	}
 
-	var data map[string]any
+	return values.Merge(layers...)
+}
+
+// setEffectiveValues records the merged values in the status of the object. Sensitive values are never
+// written to the status, only their digest is.
+func setEffectiveValues(ctx context.Context, obj v1alpha1.MutationObject, mergedValues map[string]any) (*apiextensionsv1.JSON, error) {
+	raw, err := json.Marshal(mergedValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal values: %w", err)
+	}
+
+	digest, err := values.Digest(mergedValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute digest of values: %w", err)
+	}
+
+	configValues := &apiextensionsv1.JSON{Raw: raw}
+
+	obj.GetStatus().EffectiveValuesDigest = digest
+	obj.GetStatus().EffectiveValues = nil
+
+	if !redact.FromContext(ctx).Sensitive() {
+		obj.GetStatus().EffectiveValues = configValues
+	}
+
+	return configValues, nil
+}
+
+func (m *MutationReconcileLooper) getValuesFromSource(
+	ctx context.Context, obj *v1alpha1.MutationSpec, source v1alpha1.ValuesSource, namespace, name string,
+) (map[string]any, error) {
+	var (
+		content   []byte
+		subPath   string
+		sensitive bool
+		err       error
+	)
+
 	switch {
-	case obj.ValuesFrom.FluxSource != nil:
-		content, err := m.fromFluxSource(ctx, obj)
-		if err != nil {
+	case source.FluxSource != nil:
+		if content, err = m.fromFluxSource(ctx, source.FluxSource); err != nil {
 			return nil, fmt.Errorf("failed to get values from flux source: %w", err)
 		}
-		data = content
-	case obj.ValuesFrom.ConfigMapSource != nil:
-		content, err := m.fromConfigMapSource(ctx, obj, namespace, name)
-		if err != nil {
+
+		subPath = source.FluxSource.SubPath
+	case source.ConfigMapSource != nil:
+		if content, err = m.fromConfigMapSource(ctx, source.ConfigMapSource, namespace, name); err != nil {
 			return nil, fmt.Errorf("failed to get values from configmap source: %w", err)
 		}
-		data = content
-	case obj.ValuesFrom.SourceRef != nil:
-		content, err := m.getData(ctx, obj.ValuesFrom.SourceRef)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get values from source ref: %w", err)
-		}
 
-		if err := yaml.Unmarshal(content, &data); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal values: %w", err)
+		subPath = source.ConfigMapSource.SubPath
+	case source.SecretSource != nil:
+		if content, err = m.fromSecretSource(ctx, source.SecretSource, namespace, name); err != nil {
+			return nil, fmt.Errorf("failed to get values from secret source: %w", err)
 		}
-	}
 
-	if data != nil {
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal values: %w", err)
+		subPath = source.SecretSource.SubPath
+		sensitive = true
+	case source.SourceRef != nil:
+		if content, err = m.getData(ctx, source.SourceRef); err != nil {
+			return nil, fmt.Errorf("failed to get values from source ref: %w", err)
 		}
+	default:
+		return nil, errors.New("no values found")
+	}
 
-		return &apiextensionsv1.JSON{
-			Raw: jsonData,
-		}, nil
+	// optional sources which don't exist contribute no values
+	if content == nil {
+		return make(map[string]any), nil
 	}
 
-	return nil, errors.New("no values found")
+	return m.parseValues(ctx, obj.Decryption, namespace, content, subPath, sensitive)
 }
 
-func (m *MutationReconcileLooper) fromConfigMapSource(
-	ctx context.Context,
-	obj *v1alpha1.MutationSpec,
-	namespace, name string,
+// parseValues returns the values below subPath. Content encrypted with sops is decrypted first, its values
+// are as sensitive as the ones read from a secret. They are masked in any error derived from them and parse
+// errors don't quote their content.
+func (m *MutationReconcileLooper) parseValues(
+	ctx context.Context, decryption *v1alpha1.Decryption, namespace string, content []byte, subPath string, sensitive bool,
 ) (map[string]any, error) {
-	data := make(map[string]any)
-	cm := &corev1.ConfigMap{}
-	key := types.NamespacedName{
-		Name:      obj.ValuesFrom.ConfigMapSource.SourceRef.Name,
-		Namespace: namespace,
-	}
-	if err := m.Client.Get(ctx, key, cm); err != nil {
-		if obj.ValuesFrom.ConfigMapSource.Optional && apierrors.IsNotFound(err) {
-			log.FromContext(ctx).Info("optional configmap not found for Configuration", "namespace", namespace, "configuration", name, "configmap", key.Name)
+	redactor := redact.FromContext(ctx)
 
-			return data, nil
+	if sops.IsEncrypted(content) {
+		decrypted, err := m.decrypt(ctx, decryption, namespace, content)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil, fmt.Errorf("failed to get configmap: %w", err)
+		content = decrypted
+		sensitive = true
 	}
 
-	content, found := cm.Data[obj.ValuesFrom.ConfigMapSource.Key]
-	if !found {
-		return nil, fmt.Errorf("key %s not found in configmap %s", obj.ValuesFrom.ConfigMapSource.Key, obj.ValuesFrom.ConfigMapSource.SourceRef.Name)
+	data := make(map[string]any)
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, redactor.Error(valuesParseError(err, sensitive))
 	}
 
-	err := yaml.Unmarshal([]byte(content), &data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal values: %w", err)
+	if sensitive {
+		redactor.Add(data)
 	}
 
-	if obj.ValuesFrom.ConfigMapSource.SubPath != "" {
-		data, found = extractSubpath(data, obj.ValuesFrom.ConfigMapSource.SubPath)
-		if !found {
+	if subPath != "" {
+		var found bool
+		if data, found = extractSubpath(data, subPath); !found {
 			return nil, errors.New("subPath not found")
 		}
 	}
@@ -955,64 +1593,141 @@ func (m *MutationReconcileLooper) fromConfigMapSource(
 	return data, nil
 }
 
-func (m *MutationReconcileLooper) fromFluxSource(ctx context.Context, obj *v1alpha1.MutationSpec) (map[string]any, error) {
-	data := make(map[string]any)
-	source, err := m.getSource(ctx, obj.ValuesFrom.FluxSource.SourceRef)
-	if err != nil {
-		return nil, fmt.Errorf("could not get values from source: %w", err)
+// yamlErrorLine matches the line of the offending content in errors of the YAML parser.
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// valuesParseError returns the error for values which can't be parsed. The parser quotes the offending
+// content, which can't be masked if it isn't a complete value, so for sensitive values only the line is
+// reported.
+func valuesParseError(err error, sensitive bool) error {
+	if !sensitive {
+		return fmt.Errorf("failed to unmarshal values: %w", err)
 	}
 
-	tmpDir, err := os.MkdirTemp("", "mutation-controller-")
-	if err != nil {
-		return nil, fmt.Errorf("could not create temporary directory: %w", err)
+	if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		return fmt.Errorf("failed to unmarshal values: invalid YAML in line %s", match[1])
 	}
 
-	tarSize := tar.UnlimitedUntarSize
-	const retries = 10
-	fetcher := fetch.NewArchiveFetcher(retries, tarSize, tarSize, "")
-	artifact := source.GetArtifact()
-	if artifact == nil {
-		return nil, fmt.Errorf("could not get artifact from source: %s", obj.ValuesFrom.FluxSource.SourceRef.Name)
+	return errors.New("failed to unmarshal values: invalid YAML")
+}
+
+// decrypt decrypts values encrypted with sops using the keys of the decryption secret.
+func (m *MutationReconcileLooper) decrypt(
+	ctx context.Context, decryption *v1alpha1.Decryption, namespace string, content []byte,
+) ([]byte, error) {
+	if decryption == nil || decryption.SecretRef == nil {
+		return nil, fmt.Errorf("%w: values are encrypted with sops but no decryption secret is configured", errDecryption)
 	}
-	err = fetcher.Fetch(artifact.URL, source.GetArtifact().Digest, tmpDir)
-	if err != nil {
-		return nil, fmt.Errorf("could not fetch values artifact from source: %w", err)
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{
+		Name:      decryption.SecretRef.Name,
+		Namespace: namespace,
+	}
+	if err := m.Client.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("%w: failed to get decryption secret %s: %w", errDecryption, key.Name, err)
 	}
 
-	path, err := securejoin.SecureJoin(tmpDir, obj.ValuesFrom.FluxSource.Path)
+	keys, err := sops.ParseKeys(secret.Data)
 	if err != nil {
-		return nil, fmt.Errorf("could not construct values file path: %w", err)
+		return nil, fmt.Errorf("%w: invalid decryption secret %s: %w", errDecryption, key.Name, err)
 	}
 
-	dataFile, err := os.ReadFile(path)
+	decrypted, err := sops.Decrypt(content, keys)
 	if err != nil {
-		return nil, fmt.Errorf("could not read values file: %w", err)
+		return nil, fmt.Errorf("%w with keys of secret %s: %w", errDecryption, key.Name, err)
 	}
 
-	if err := yaml.Unmarshal(dataFile, &data); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal values: %w", err)
+	return decrypted, nil
+}
+
+// fromConfigMapSource returns the content of the key of a configmap. The content is nil if an optional
+// configmap doesn't exist.
+func (m *MutationReconcileLooper) fromConfigMapSource(
+	ctx context.Context,
+	source *v1alpha1.ConfigMapSource,
+	namespace, name string,
+) ([]byte, error) {
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{
+		Name:      source.SourceRef.Name,
+		Namespace: namespace,
 	}
+	if err := m.Client.Get(ctx, key, cm); err != nil {
+		if source.Optional && apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Info("optional configmap not found for Configuration", "namespace", namespace, "configuration", name, "configmap", key.Name)
 
-	var found bool
-	if obj.ValuesFrom.FluxSource.SubPath != "" {
-		data, found = extractSubpath(data, obj.ValuesFrom.FluxSource.SubPath)
-		if !found {
-			return nil, errors.New("subPath not found")
+			return nil, nil
 		}
+
+		return nil, fmt.Errorf("failed to get configmap: %w", err)
 	}
 
-	return data, nil
+	content, found := cm.Data[source.Key]
+	if !found {
+		return nil, fmt.Errorf("key %s not found in configmap %s", source.Key, source.SourceRef.Name)
+	}
+
+	return []byte(content), nil
+}
+
+// fromSecretSource returns the content of the key of a secret. The content is nil if an optional secret
+// doesn't exist.
+func (m *MutationReconcileLooper) fromSecretSource(
+	ctx context.Context,
+	source *v1alpha1.SecretSource,
+	namespace, name string,
+) ([]byte, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{
+		Name:      source.SourceRef.Name,
+		Namespace: namespace,
+	}
+	if err := m.Client.Get(ctx, key, secret); err != nil {
+		if source.Optional && apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Info("optional secret not found for Configuration", "namespace", namespace, "configuration", name, "secret", key.Name)
+
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	content, found := secret.Data[source.Key]
+	if !found {
+		return nil, fmt.Errorf("key %s not found in secret %s", source.Key, source.SourceRef.Name)
+	}
+
+	return content, nil
+}
+
+func (m *MutationReconcileLooper) fromFluxSource(ctx context.Context, valuesSource *v1alpha1.FluxValuesSource) ([]byte, error) {
+	dataFile, err := m.fetchFileFromSource(ctx, &v1alpha1.ObjectReference{
+		NamespacedObjectKindReference: valuesSource.SourceRef,
+		Path:                          valuesSource.Path,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read values file: %w", err)
+	}
+
+	return dataFile, nil
 }
 
 func (m *MutationReconcileLooper) mutate(
 	ctx context.Context,
+	obj v1alpha1.MutationObject,
 	mutationSpec *v1alpha1.MutationSpec,
+	configRef *v1alpha1.ObjectReference,
 	sourceData, configData []byte,
-	namespace, name string,
 ) (string, error) {
+	config, err := configdata.Parse(configDataFile(configRef), configData)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal content: %w", err)
+	}
+
 	// if values are not nil then this is configuration
-	if mutationSpec.Values != nil || mutationSpec.ValuesFrom != nil {
-		sourceDir, err := m.configure(ctx, sourceData, configData, mutationSpec, namespace, name)
+	if mutationSpec.Values != nil || len(mutationSpec.GetValuesSources()) > 0 {
+		sourceDir, err := m.configure(ctx, obj, sourceData, config, mutationSpec, configRef)
 		if err != nil {
 			return "", fmt.Errorf("failed to configure resource: %w", err)
 		}
@@ -1021,21 +1736,34 @@ func (m *MutationReconcileLooper) mutate(
 	}
 
 	// if values are nil then this is localization
-	return m.localize(ctx, mutationSpec, sourceData, configData)
+	return m.localize(ctx, obj, configRef, sourceData, config)
+}
+
+// configDataFile returns the name errors in the config data of the reference are reported with.
+func configDataFile(configRef *v1alpha1.ObjectReference) string {
+	switch {
+	case configRef.Path != "":
+		return configRef.Path
+	case configRef.ResourceRef != nil:
+		return configRef.ResourceRef.Name
+	default:
+		return configRef.Name
+	}
 }
 
 func (m *MutationReconcileLooper) mutateConfigRef(
 	ctx context.Context,
 	obj v1alpha1.MutationObject,
 	spec *v1alpha1.MutationSpec,
+	configRef *v1alpha1.ObjectReference,
 	sourceData []byte,
 ) (string, ocmmetav1.Identity, error) {
-	configData, err := m.getData(ctx, spec.ConfigRef)
+	configData, err := m.getData(ctx, configRef)
 	if err != nil {
 		return "", ocmmetav1.Identity{}, fmt.Errorf("failed to get data for config ref: %w", err)
 	}
 
-	snapshotID, err := m.getIdentity(ctx, spec.ConfigRef)
+	snapshotID, err := m.getIdentity(ctx, configRef)
 
 	// 2024-07-10 d :
 	// Another part of fix for #68
@@ -1054,18 +1782,29 @@ func (m *MutationReconcileLooper) mutateConfigRef(
 
 	obj.GetStatus().LatestConfigVersion = snapshotID[v1alpha1.ComponentVersionKey]
 
-	sourceDir, err := m.mutate(ctx, spec, sourceData, configData, obj.GetNamespace(), obj.GetName())
+	matched := len(obj.GetStatus().MatchedFiles)
+
+	sourceDir, err := m.mutate(ctx, obj, spec, configRef, sourceData, configData)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	chartVersion, err := helmChartVersion(sourceDir, obj.GetStatus().MatchedFiles[matched:])
 	if err != nil {
 		return "", ocmmetav1.Identity{}, err
 	}
 
+	if chartVersion != "" {
+		snapshotID[v1alpha1.ResourceHelmChartVersion] = chartVersion
+	}
+
 	return sourceDir, snapshotID, nil
 }
 
 func (m *MutationReconcileLooper) mutatePatchStrategicMerge(
 	ctx context.Context,
 	obj v1alpha1.MutationObject,
-	mutationSpec *v1alpha1.MutationSpec,
+	patch *v1alpha1.PatchStrategicMerge,
 	sourceData []byte,
 ) (string, ocmmetav1.Identity, error) {
 	// DO NOT Defer remove this, it will be removed once it has been tarred.
@@ -1082,64 +1821,76 @@ func (m *MutationReconcileLooper) mutatePatchStrategicMerge(
 		return "", nil, err
 	}
 
+	identity, err := m.fetchPatchSource(ctx, obj, patch.Source.SourceRef, workDir)
+	if err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	sourcePath := patch.Source.Path
+	targetPath := patch.Target.Path
+	if _, err := m.strategicMergePatch(sourceData, tmpDir, workDir, sourcePath, targetPath); err != nil {
+		return "", ocmmetav1.Identity{}, err
+	}
+
+	return workDir, identity, nil
+}
+
+// fetchPatchSource fetches the content of a Flux source or of the snapshot of an OCM object into workDir and
+// returns the identity of the content.
+func (m *MutationReconcileLooper) fetchPatchSource(
+	ctx context.Context,
+	obj v1alpha1.MutationObject,
+	ref meta.NamespacedObjectKindReference,
+	workDir string,
+) (ocmmetav1.Identity, error) {
 	var identity ocmmetav1.Identity
 
-	switch mutationSpec.PatchStrategicMerge.Source.SourceRef.Kind {
-	case "GitRepository":
-		gitSource, err := m.getSource(ctx, mutationSpec.PatchStrategicMerge.Source.SourceRef)
+	switch ref.Kind {
+	case sourcev1.GitRepositoryKind, sourcev1.BucketKind, sourcev1.OCIRepositoryKind, sourcev1.HelmChartKind:
+		artifact, err := m.fetchSourceArtifact(ctx, ref, workDir)
 		if err != nil {
-			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to get patch source: %w", err)
+			return nil, fmt.Errorf("failed to get patch source: %w", err)
 		}
 
-		obj.GetStatus().LatestPatchSourceVersion = gitSource.GetArtifact().Revision
-		tarSize := tar.UnlimitedUntarSize
-		const retries = 10
-		fetcher := fetch.NewArchiveFetcher(retries, tarSize, tarSize, "")
-		if err := fetcher.Fetch(gitSource.GetArtifact().URL, gitSource.GetArtifact().Digest, workDir); err != nil {
-			return "", nil, err
-		}
+		obj.GetStatus().LatestPatchSourceVersion = artifact.Revision
 		identity = ocmmetav1.Identity{
-			v1alpha1.SourceNameKey:             mutationSpec.PatchStrategicMerge.Source.SourceRef.Name,
-			v1alpha1.SourceNamespaceKey:        mutationSpec.PatchStrategicMerge.Source.SourceRef.Namespace,
-			v1alpha1.SourceArtifactChecksumKey: gitSource.GetArtifact().Digest,
+			v1alpha1.SourceNameKey:             ref.Name,
+			v1alpha1.SourceNamespaceKey:        ref.Namespace,
+			v1alpha1.SourceArtifactChecksumKey: artifact.Digest,
 		}
 	case v1alpha1.ResourceKind, v1alpha1.ConfigurationKind, v1alpha1.LocalizationKind:
 		data, digest, err := m.fetchDataFromObjectReference(ctx, &v1alpha1.ObjectReference{
-			NamespacedObjectKindReference: mutationSpec.PatchStrategicMerge.Source.SourceRef,
+			NamespacedObjectKindReference: ref,
 		}, false)
 		if err != nil {
-			return "", ocmmetav1.Identity{}, fmt.Errorf("failed to fetch data from source: %w", err)
+			return nil, fmt.Errorf("failed to fetch data from source: %w", err)
 		}
 
 		identity = ocmmetav1.Identity{
-			v1alpha1.SourceNameKey:             mutationSpec.PatchStrategicMerge.Source.SourceRef.Name,
-			v1alpha1.SourceNamespaceKey:        mutationSpec.PatchStrategicMerge.Source.SourceRef.Namespace,
+			v1alpha1.SourceNameKey:             ref.Name,
+			v1alpha1.SourceNamespaceKey:        ref.Namespace,
 			v1alpha1.SourceArtifactChecksumKey: digest,
 		}
 
 		if _, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
 			if err := tar.Untar(bytes.NewReader(data), workDir); err != nil {
-				return "", ocmmetav1.Identity{}, fmt.Errorf("failed to untar data from source: %w", err)
+				return nil, fmt.Errorf("failed to untar data from source: %w", err)
 			}
 		} else {
 			const perm = 0o755
 			if err := os.MkdirAll(workDir, perm); err != nil {
-				return "", ocmmetav1.Identity{}, fmt.Errorf("failed to create work dir: %w", err)
+				return nil, fmt.Errorf("failed to create work dir: %w", err)
 			}
 
 			if err := untar.Untar(bytes.NewReader(data), workDir); err != nil {
-				return "", ocmmetav1.Identity{}, fmt.Errorf("failed to untar data from source without gzip: %w", err)
+				return nil, fmt.Errorf("failed to untar data from source without gzip: %w", err)
 			}
 		}
+	default:
+		return nil, fmt.Errorf("patch source `%s` kind '%s' not supported", ref.Name, ref.Kind)
 	}
 
-	sourcePath := mutationSpec.PatchStrategicMerge.Source.Path
-	targetPath := mutationSpec.PatchStrategicMerge.Target.Path
-	if _, err := m.strategicMergePatch(sourceData, tmpDir, workDir, sourcePath, targetPath); err != nil {
-		return "", ocmmetav1.Identity{}, err
-	}
-
-	return workDir, identity, nil
+	return identity, nil
 }
 
 // Recursive function to extract the subpath from the data map.
@@ -1,25 +1,42 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
+	"filippo.io/age"
+	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"ocm.software/ocm/api/ocm"
 	v1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
 	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
 	"ocm.software/ocm/api/ocm/ocmutils/localize"
 	ocmruntime "ocm.software/ocm/api/utils/runtime"
 
 	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/access"
+	cachefakes "github.com/open-component-model/ocm-controller/pkg/cache/fakes"
 	"github.com/open-component-model/ocm-controller/pkg/component"
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+	"github.com/open-component-model/ocm-controller/pkg/envelope"
+	ocmfake "github.com/open-component-model/ocm-controller/pkg/fakes"
+	"github.com/open-component-model/ocm-controller/pkg/ocm/fakes"
 	"github.com/open-component-model/ocm-controller/pkg/snapshot"
+	"github.com/open-component-model/ocm-controller/pkg/substitute"
 )
 
 type componentGenerator struct {
@@ -61,9 +78,7 @@ func TestGenerateSubstitutionsUseDefaults(t *testing.T) {
 	vls := `dmi:
   some_aws_val: blah`
 
-	schm := ""
-
-	oSbs, err := m.generateSubstitutions(iSbs, []byte(dflts), []byte(vls), []byte(schm))
+	oSbs, err := m.generateSubstitutions(iSbs, []byte(dflts), []byte(vls))
 	assert.NoError(t, err)
 	assert.Equal(t, len(iSbs), len(oSbs))
 	var expected json.RawMessage
@@ -259,3 +274,630 @@ func makeReference(name, version, component string) v3alpha1.Reference {
 		ComponentName: component,
 	}
 }
+
+func TestCompositeIdentity(t *testing.T) {
+	config := v1.Identity{
+		v1alpha1.ComponentVersionKey:   "v0.0.1",
+		v1alpha1.MutationObjectUUIDKey: "uuid",
+	}
+	patch := v1.Identity{
+		v1alpha1.SourceNameKey:             "patch-repo",
+		v1alpha1.SourceArtifactChecksumKey: "checksum",
+	}
+
+	single, err := compositeIdentity([]v1.Identity{config})
+	require.NoError(t, err)
+	assert.Equal(t, config, single)
+
+	composite, err := compositeIdentity([]v1.Identity{config, patch})
+	require.NoError(t, err)
+	assert.Equal(t, "v0.0.1", composite[v1alpha1.ComponentVersionKey])
+	assert.NotEmpty(t, composite[v1alpha1.MutationStepsDigestKey])
+	assert.NotContains(t, config, v1alpha1.MutationStepsDigestKey)
+
+	reordered, err := compositeIdentity([]v1.Identity{patch, config})
+	require.NoError(t, err)
+	assert.NotEqual(t, composite[v1alpha1.MutationStepsDigestKey], reordered[v1alpha1.MutationStepsDigestKey])
+
+	changed, err := compositeIdentity([]v1.Identity{config, {
+		v1alpha1.SourceNameKey:             "patch-repo",
+		v1alpha1.SourceArtifactChecksumKey: "other",
+	}})
+	require.NoError(t, err)
+	assert.NotEqual(t, composite[v1alpha1.MutationStepsDigestKey], changed[v1alpha1.MutationStepsDigestKey])
+}
+
+func TestCreateSubstitutionRulesHelmValues(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "podinfo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "podinfo", "Chart.yaml"), []byte("apiVersion: v2\nname: podinfo\nversion: 6.1.0\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "podinfo", "values.yaml"), []byte("# replicas of podinfo\nreplicaCount: 1\n"), 0o600))
+
+	configData := `apiVersion: config.ocm.software/v1alpha1
+kind: ConfigData
+configuration:
+  defaults:
+    replicas: 1
+  helmValues:
+    replicaCount: (( replicas ))
+`
+
+	m := &MutationReconcileLooper{}
+	rules, matches, err := m.createSubstitutionRulesForConfigurationValues(
+		parseConfigData(t, configData), &apiextensionsv1.JSON{Raw: []byte(`{"replicas": 3}`)}, sourceDir, nil, nil,
+	)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+	assert.Equal(t, []v1alpha1.RuleMatch{{
+		Rule:    "configuration.helmValues",
+		Pattern: "podinfo/values.yaml",
+		Files:   []string{"podinfo/values.yaml"},
+	}}, matches)
+
+	content, err := os.ReadFile(filepath.Join(sourceDir, "podinfo", "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "# replicas of podinfo\nreplicaCount: 3\n", string(content))
+
+	// the snapshot of the chart is tagged with its version
+	version, err := helmChartVersion(sourceDir, matches)
+	require.NoError(t, err)
+	assert.Equal(t, "6.1.0", version)
+
+	version, err = helmChartVersion(sourceDir, nil)
+	require.NoError(t, err)
+	assert.Empty(t, version)
+
+	_, _, err = m.createSubstitutionRulesForConfigurationValues(
+		parseConfigData(t, configData), &apiextensionsv1.JSON{Raw: []byte(`{"replicas": 3}`)}, t.TempDir(), nil, nil,
+	)
+	assert.ErrorContains(t, err, "helm values require a helm chart resource")
+}
+
+func TestCreateSubstitutionRulesWhen(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "deploy.yaml"), []byte("spec:\n  replicas: 1\n"), 0o600))
+
+	configData := `apiVersion: config.ocm.software/v1alpha1
+kind: ConfigData
+configuration:
+  defaults:
+    replicas: 1
+  rules:
+  - when: values.tracing != _|_
+    value: (( tracing ))
+    file: missing.yaml
+    path: spec.tracing
+  - when: values.replicas > 1
+    value: (( replicas ))
+    file: deploy.yaml
+    path: spec.replicas
+`
+
+	conditions := &ruleConditions{
+		scope: func() (map[string]any, error) {
+			return map[string]any{"values": map[string]any{"replicas": 3}}, nil
+		},
+	}
+
+	m := &MutationReconcileLooper{}
+	rules, matches, err := m.createSubstitutionRulesForConfigurationValues(
+		parseConfigData(t, configData), &apiextensionsv1.JSON{Raw: []byte(`{"replicas": 3}`)}, sourceDir, nil, conditions,
+	)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "deploy.yaml", rules[0].File)
+	assert.Equal(t, []v1alpha1.RuleMatch{{
+		Rule:    "configuration.rules[1]",
+		Pattern: "deploy.yaml",
+		Files:   []string{"deploy.yaml"},
+	}}, matches)
+	assert.Equal(t, []v1alpha1.SkippedRule{{
+		Rule: "configuration.rules[0]",
+		When: "values.tracing != _|_",
+	}}, conditions.skippedRules())
+}
+
+func TestCreateSubstitutionRulesProfile(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "deploy.yaml"), []byte("spec:\n  replicas: 1\n  host: dev\n"), 0o600))
+
+	configData := `apiVersion: config.ocm.software/v1alpha1
+kind: ConfigData
+configuration:
+  defaults:
+    replicas: 1
+    host: dev.example.com
+  rules:
+  - value: (( replicas ))
+    file: deploy.yaml
+    path: spec.replicas
+  profiles:
+  - name: production
+    defaults:
+      replicas: 3
+    rules:
+    - value: (( host ))
+      file: deploy.yaml
+      path: spec.host
+`
+
+	m := &MutationReconcileLooper{}
+	profile := &configProfile{name: "production"}
+	rules, matches, err := m.createSubstitutionRulesForConfigurationValues(
+		parseConfigData(t, configData), &apiextensionsv1.JSON{Raw: []byte(`{}`)}, sourceDir, profile, nil,
+	)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "production", profile.applied)
+	assert.Equal(t, []v1alpha1.RuleMatch{{
+		Rule:    "configuration.rules[0]",
+		Pattern: "deploy.yaml",
+		Files:   []string{"deploy.yaml"},
+	}, {
+		Rule:    "configuration.profiles[0].rules[0]",
+		Pattern: "deploy.yaml",
+		Files:   []string{"deploy.yaml"},
+	}}, matches)
+
+	assert.JSONEq(t, `3`, string(rules[0].Value))
+
+	labelled := &configProfile{name: "staging", fromNamespace: true}
+	rules, _, err = m.createSubstitutionRulesForConfigurationValues(
+		parseConfigData(t, configData), &apiextensionsv1.JSON{Raw: []byte(`{}`)}, sourceDir, labelled, nil,
+	)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Empty(t, labelled.applied)
+
+	_, _, err = m.createSubstitutionRulesForConfigurationValues(
+		parseConfigData(t, configData), &apiextensionsv1.JSON{Raw: []byte(`{}`)}, sourceDir, &configProfile{name: "staging"}, nil,
+	)
+	assert.ErrorContains(t, err, "profile staging not found in config data")
+}
+
+func TestSelectProfile(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "staging",
+		Labels: map[string]string{v1alpha1.ConfigProfileLabel: "staging"},
+	}}
+	labelled := &v1alpha1.Configuration{ObjectMeta: metav1.ObjectMeta{Name: "labelled", Namespace: "staging"}}
+	selected := &v1alpha1.Configuration{
+		ObjectMeta: metav1.ObjectMeta{Name: "selected", Namespace: "staging"},
+		Spec:       v1alpha1.ConfigurationSpec{Profile: "production"},
+	}
+	client := env.FakeKubeClient(WithObjects(namespace, labelled, selected))
+
+	m := &MutationReconcileLooper{Client: client}
+
+	testCases := []struct {
+		name     string
+		obj      v1alpha1.MutationObject
+		expected *configProfile
+	}{
+		{
+			name:     "profile of the spec",
+			obj:      selected,
+			expected: &configProfile{name: "production"},
+		},
+		{
+			name:     "profile of the namespace label",
+			obj:      labelled,
+			expected: &configProfile{name: "staging", fromNamespace: true},
+		},
+		{
+			name:     "missing namespace",
+			obj:      &v1alpha1.Configuration{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "missing"}},
+			expected: &configProfile{},
+		},
+		{
+			name:     "localization",
+			obj:      &v1alpha1.Localization{ObjectMeta: metav1.ObjectMeta{Name: "localization", Namespace: "staging"}},
+			expected: &configProfile{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			profile, err := m.selectProfile(context.Background(), tc.obj)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, profile)
+		})
+	}
+
+	r := &ConfigurationReconciler{Client: client}
+	requests := r.findObjectsForNamespace(context.Background(), namespace)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "staging/labelled", requests[0].String())
+}
+
+func parseConfigData(t *testing.T, data string) *configdata.ConfigData {
+	t.Helper()
+
+	config, err := configdata.Parse("config.yaml", []byte(data))
+	require.NoError(t, err)
+
+	return config
+}
+
+func TestPerformLocalization(t *testing.T) {
+	const digest = "sha256:7f0168496f273c1e2095703a050128114d339c580b0906cd124a93b66ae471e2"
+
+	testCases := []struct {
+		name           string
+		imageReference string
+		rule           configdata.LocalizationRule
+		preferDigest   bool
+		headDigest     string
+		headErr        error
+		expected       map[string]string
+		expectedErr    string
+		expectHead     bool
+	}{
+		{
+			name:           "tag only",
+			imageReference: "ghcr.io/org/podinfo:6.1.0",
+			rule:           configdata.LocalizationRule{Image: "spec.image", Tag: "spec.tag"},
+			expected: map[string]string{
+				"image": "ghcr.io/org/podinfo:6.1.0",
+				"tag":   "6.1.0",
+			},
+		},
+		{
+			name:           "tag only with digest fetched from the registry",
+			imageReference: "ghcr.io/org/podinfo:6.1.0",
+			rule:           configdata.LocalizationRule{Digest: "spec.digest", ImageWithDigest: "spec.pinned"},
+			headDigest:     digest,
+			expected: map[string]string{
+				"digest":          digest,
+				"imageWithDigest": "ghcr.io/org/podinfo@" + digest,
+			},
+			expectHead: true,
+		},
+		{
+			name:           "tag only preferring digest",
+			imageReference: "ghcr.io/org/podinfo:6.1.0",
+			rule:           configdata.LocalizationRule{Image: "spec.image", Tag: "spec.tag"},
+			preferDigest:   true,
+			headDigest:     digest,
+			expected: map[string]string{
+				"image": "ghcr.io/org/podinfo@" + digest,
+				"tag":   "6.1.0",
+			},
+			expectHead: true,
+		},
+		{
+			name:           "digest only",
+			imageReference: "ghcr.io/org/podinfo@" + digest,
+			rule:           configdata.LocalizationRule{Image: "spec.image", Digest: "spec.digest", ImageWithDigest: "spec.pinned"},
+			expected: map[string]string{
+				"image":           "ghcr.io/org/podinfo@" + digest,
+				"digest":          digest,
+				"imageWithDigest": "ghcr.io/org/podinfo@" + digest,
+			},
+		},
+		{
+			name:           "tag and digest preferring digest",
+			imageReference: "ghcr.io/org/podinfo:6.1.0@" + digest,
+			rule:           configdata.LocalizationRule{Image: "spec.image", Tag: "spec.tag", Digest: "spec.digest"},
+			preferDigest:   true,
+			expected: map[string]string{
+				"image":  "ghcr.io/org/podinfo@" + digest,
+				"tag":    "6.1.0",
+				"digest": digest,
+			},
+		},
+		{
+			name:           "failed HEAD lookup",
+			imageReference: "ghcr.io/org/podinfo:6.1.0",
+			rule:           configdata.LocalizationRule{Digest: "spec.digest"},
+			headErr:        errors.New("unauthorized"),
+			expectedErr:    "failed to get digest of resource podinfo: unauthorized",
+			expectHead:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeOcm := &fakes.MockFetcher{}
+			fakeOcm.GetImageDigestReturns(tc.headDigest, tc.headErr)
+
+			m := &MutationReconcileLooper{OCMClient: fakeOcm}
+			comp := newTestComponent(&ocmfake.Resource[*ocm.ResourceMeta]{
+				Name:          "podinfo",
+				Version:       "6.1.0",
+				Type:          "ociImage",
+				AccessOptions: []ocmfake.AccessOptionFunc{withImageReference(tc.imageReference)},
+			})
+
+			rule := tc.rule
+			rule.Resource = configdata.ResourceItem{Name: "podinfo"}
+
+			var localizations substitute.Substitutions
+			err := m.performLocalization(
+				context.Background(), nil, rule, []string{"deploy.yaml"}, &localizations, nil, comp, access.Options{}, tc.preferDigest,
+			)
+			assert.Equal(t, tc.expectHead, !fakeOcm.GetImageDigestWasNotCalled())
+
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			values := map[string]string{}
+			for _, l := range localizations {
+				var value string
+				require.NoError(t, json.Unmarshal(l.Value, &value))
+				assert.Equal(t, "deploy.yaml", l.File)
+				values[l.Name] = value
+			}
+			assert.Equal(t, tc.expected, values)
+		})
+	}
+}
+
+// newTestComponent returns a component version containing the given resources.
+func newTestComponent(resources ...*ocmfake.Resource[*ocm.ResourceMeta]) *ocmfake.Component {
+	comp := &ocmfake.Component{
+		Name:      "github.com/open-component-model/test",
+		Version:   "v1.0.0",
+		Resources: resources,
+	}
+
+	for _, r := range resources {
+		r.Component = comp
+	}
+
+	return comp
+}
+
+// withImageReference replaces the access of a resource with an ociArtifact access of the image.
+func withImageReference(ref string) ocmfake.AccessOptionFunc {
+	return func(m map[string]any) {
+		clear(m)
+		m["type"] = "ociArtifact"
+		m["imageReference"] = ref
+	}
+}
+
+func TestResolveResource(t *testing.T) {
+	comp := newTestComponent(
+		&ocmfake.Resource[*ocm.ResourceMeta]{Name: "podinfo", Version: "6.1.0"},
+		&ocmfake.Resource[*ocm.ResourceMeta]{Name: "podinfo", Version: "6.2.0"},
+		&ocmfake.Resource[*ocm.ResourceMeta]{Name: "redis", Version: "7.0.0"},
+		&ocmfake.Resource[*ocm.ResourceMeta]{Name: "nginx", Version: "1.0.0", ExtraIdentity: v1.Identity{"arch": "amd64"}},
+		&ocmfake.Resource[*ocm.ResourceMeta]{Name: "nginx", Version: "1.0.0", ExtraIdentity: v1.Identity{"arch": "arm64"}},
+	)
+
+	testCases := []struct {
+		name            string
+		item            configdata.ResourceItem
+		refPath         []v1.Identity
+		expectedVersion string
+		expectedExtra   v1.Identity
+		expectedErr     string
+	}{
+		{
+			name:            "unique name",
+			item:            configdata.ResourceItem{Name: "redis"},
+			expectedVersion: "7.0.0",
+		},
+		{
+			name:            "unique name pinned to its version",
+			item:            configdata.ResourceItem{Name: "redis", Version: "7.0.0"},
+			expectedVersion: "7.0.0",
+		},
+		{
+			name:        "unique name pinned to another version",
+			item:        configdata.ResourceItem{Name: "redis", Version: "8.0.0"},
+			expectedErr: "resource redis has version 7.0.0, but 8.0.0 was requested",
+		},
+		{
+			name:            "version pinned",
+			item:            configdata.ResourceItem{Name: "podinfo", Version: "6.2.0"},
+			expectedVersion: "6.2.0",
+		},
+		{
+			name:        "ambiguous",
+			item:        configdata.ResourceItem{Name: "podinfo"},
+			expectedErr: "not found",
+		},
+		{
+			name:        "ambiguous pinned to a missing version",
+			item:        configdata.ResourceItem{Name: "podinfo", Version: "6.3.0"},
+			expectedErr: "not found",
+		},
+		{
+			name:            "extra identity",
+			item:            configdata.ResourceItem{Name: "nginx", ExtraIdentity: map[string]string{"arch": "arm64"}},
+			expectedVersion: "1.0.0",
+			expectedExtra:   v1.Identity{"arch": "arm64"},
+		},
+		{
+			name:        "missing",
+			item:        configdata.ResourceItem{Name: "missing"},
+			expectedErr: "not found",
+		},
+		{
+			name:        "failed lookup of a version pinned reference",
+			item:        configdata.ResourceItem{Name: "podinfo", Version: "6.2.0"},
+			refPath:     []v1.Identity{{"name": "missing"}},
+			expectedErr: "failed to resolve resource podinfo with version 6.2.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resource, err := resolveResource(tc.item, tc.refPath, comp)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.item.Name, resource.Meta().Name)
+			assert.Equal(t, tc.expectedVersion, resource.Meta().Version)
+			assert.Equal(t, tc.expectedExtra, resource.Meta().ExtraIdentity)
+		})
+	}
+}
+
+func TestFetchPatchSourceUnsupportedKind(t *testing.T) {
+	m := &MutationReconcileLooper{}
+	identity, err := m.fetchPatchSource(context.Background(), &v1alpha1.Configuration{}, meta.NamespacedObjectKindReference{
+		Kind: "ConfigMap",
+		Name: "patches",
+	}, t.TempDir())
+	assert.EqualError(t, err, "patch source `patches` kind 'ConfigMap' not supported")
+	assert.Nil(t, identity)
+}
+
+func TestParseValuesError(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		sensitive     bool
+		expectedError string
+	}{
+		{
+			name:          "plain values keep the parser message",
+			content:       "port: !!int http\n",
+			expectedError: "failed to unmarshal values: error converting YAML to JSON: yaml: cannot decode !!str `http` as a !!int",
+		},
+		{
+			name:          "sensitive values aren't quoted",
+			content:       "password: !!int s3cret-token\n",
+			sensitive:     true,
+			expectedError: "failed to unmarshal values: invalid YAML",
+		},
+		{
+			name:          "sensitive values report the line only",
+			content:       "user: admin\npassword: s3cret: token\n",
+			sensitive:     true,
+			expectedError: "failed to unmarshal values: invalid YAML in line 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &MutationReconcileLooper{}
+			_, err := m.parseValues(context.Background(), nil, "default", []byte(tc.content), "", tc.sensitive)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestGetSnapshotBytesEncryption(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	kek, err := envelope.NewKey([]byte(identity.String()))
+	require.NoError(t, err)
+
+	content := []byte("password: s3cret")
+	encrypted := &bytes.Buffer{}
+	require.NoError(t, envelope.Encrypt(encrypted, bytes.NewReader(content), kek))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "snapshot-key",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			envelope.KeyField: []byte(identity.String()),
+		},
+	}
+	encryption := &v1alpha1.SnapshotEncryption{
+		SecretRef: meta.LocalObjectReference{Name: secret.Name},
+	}
+
+	testCases := []struct {
+		name   string
+		spec   *v1alpha1.SnapshotEncryption
+		status *v1alpha1.SnapshotEncryption
+		cached []byte
+	}{
+		{
+			name:   "reconciled content is encrypted, the spec already isn't",
+			status: encryption,
+			cached: encrypted.Bytes(),
+		},
+		{
+			name:   "reconciled content is plain, the spec is already encrypted",
+			spec:   encryption,
+			cached: content,
+		},
+		{
+			name:   "reconciled content is encrypted",
+			spec:   encryption,
+			status: encryption,
+			cached: encrypted.Bytes(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshot := &v1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-snapshot",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.SnapshotSpec{
+					Identity: v1.Identity{
+						v1alpha1.ComponentNameKey:    "component-name",
+						v1alpha1.ComponentVersionKey: "v0.0.1",
+						v1alpha1.ResourceNameKey:     "resource-name",
+						v1alpha1.ResourceVersionKey:  "v0.0.5",
+					},
+					Digest:     "digest-2",
+					Encryption: tc.spec,
+				},
+				Status: v1alpha1.SnapshotStatus{
+					LastReconciledDigest:     "digest-1",
+					LastReconciledEncryption: tc.status,
+				},
+			}
+
+			cache := &cachefakes.FakeCache{}
+			cache.FetchDataByDigestReturns(io.NopCloser(bytes.NewReader(tc.cached)), nil)
+
+			m := &MutationReconcileLooper{
+				Client: env.FakeKubeClient(WithObjects(secret)),
+				Cache:  cache,
+			}
+
+			data, err := m.getSnapshotBytes(context.Background(), snapshot, false)
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+
+			assert.Equal(t, "digest-1", cache.FetchDataByDigestCallingArgumentsOnCall(0)[1])
+		})
+	}
+}
+
+func TestQualifyRules(t *testing.T) {
+	status := &v1alpha1.MutationStatus{
+		MatchedFiles: []v1alpha1.RuleMatch{
+			{Rule: "steps[0].localization[0]"},
+			{Rule: "localization[0]"},
+			{Rule: "localization[1]"},
+		},
+		SkippedRules: []v1alpha1.SkippedRule{
+			{Rule: "configuration.rules[0]"},
+		},
+	}
+
+	qualifyRules(status, "steps[1].", 1, 0)
+
+	assert.Equal(t, []v1alpha1.RuleMatch{
+		{Rule: "steps[0].localization[0]"},
+		{Rule: "steps[1].localization[0]"},
+		{Rule: "steps[1].localization[1]"},
+	}, status.MatchedFiles)
+	assert.Equal(t, []v1alpha1.SkippedRule{
+		{Rule: "steps[1].configuration.rules[0]"},
+	}, status.SkippedRules)
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+)
+
+// ConfigProfileLabelChangedPredicate passes updates of namespaces which change the label selecting the profile
+// of the config data.
+type ConfigProfileLabelChangedPredicate struct {
+	predicate.Funcs
+}
+
+func (ConfigProfileLabelChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+
+	return e.ObjectOld.GetLabels()[v1alpha1.ConfigProfileLabel] != e.ObjectNew.GetLabels()[v1alpha1.ConfigProfileLabel]
+}
@@ -254,6 +254,7 @@ func (r *ResourceReconciler) reconcile(
 			Identity: identity,
 			Digest:   digest,
 			Tag:      version,
+			Artifact: component.GetArtifactMetadata(componentDescriptor, obj.Spec.SourceRef.ResourceRef),
 		}
 
 		return nil
@@ -117,6 +117,8 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 
 	obj.Status.LastReconciledDigest = obj.Spec.Digest
 	obj.Status.LastReconciledTag = obj.Spec.Tag
+	obj.Status.LastReconciledEncryption = obj.Spec.Encryption.DeepCopy()
+	obj.Status.Artifact = obj.Spec.Artifact.DeepCopy()
 
 	scheme := httpsScheme
 	if r.InsecureSkipVerify {
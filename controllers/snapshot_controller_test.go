@@ -41,6 +41,9 @@ func TestSnapshotReconciler(t *testing.T) {
 			},
 			Digest: "digest-1",
 			Tag:    "1234",
+			Encryption: &v1alpha1.SnapshotEncryption{
+				SecretRef: meta.LocalObjectReference{Name: "snapshot-key"},
+			},
 		},
 	}
 	client := env.FakeKubeClient(WithObjects(snapshot))
@@ -67,6 +70,7 @@ func TestSnapshotReconciler(t *testing.T) {
 	assert.True(t, conditions.IsTrue(snapshot, meta.ReadyCondition))
 	assert.Equal(t, "digest-1", snapshot.Status.LastReconciledDigest)
 	assert.Equal(t, "1234", snapshot.Status.LastReconciledTag)
+	assert.Equal(t, snapshot.Spec.Encryption, snapshot.Status.LastReconciledEncryption)
 	assert.Equal(t, "https://127.0.0.1:5000/sha-16038726184537443379", snapshot.Status.RepositoryURL)
 
 	close(recorder.Events)
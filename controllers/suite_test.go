@@ -185,18 +185,20 @@ var (
 			Name:      "test-localization",
 			Namespace: "default",
 		},
-		Spec: v1alpha1.MutationSpec{
-			Interval: metav1.Duration{},
-			ConfigRef: &v1alpha1.ObjectReference{
-				NamespacedObjectKindReference: meta.NamespacedObjectKindReference{
-					Kind:      v1alpha1.ComponentVersionKind,
-					Name:      DefaultComponent.Name,
-					Namespace: DefaultComponent.Namespace,
-				},
-				ResourceRef: &v1alpha1.ResourceReference{
-					ElementMeta: v1alpha1.ElementMeta{
-						Name:    "introspect-image",
-						Version: "1.0.0",
+		Spec: v1alpha1.LocalizationSpec{
+			MutationSpec: v1alpha1.MutationSpec{
+				Interval: metav1.Duration{},
+				ConfigRef: &v1alpha1.ObjectReference{
+					NamespacedObjectKindReference: meta.NamespacedObjectKindReference{
+						Kind:      v1alpha1.ComponentVersionKind,
+						Name:      DefaultComponent.Name,
+						Namespace: DefaultComponent.Namespace,
+					},
+					ResourceRef: &v1alpha1.ResourceReference{
+						ElementMeta: v1alpha1.ElementMeta{
+							Name:    "introspect-image",
+							Version: "1.0.0",
+						},
 					},
 				},
 			},
@@ -211,28 +213,30 @@ var (
 			Name:      "test-configuration",
 			Namespace: "default",
 		},
-		Spec: v1alpha1.MutationSpec{
-			Interval: metav1.Duration{},
-			ConfigRef: &v1alpha1.ObjectReference{
-				NamespacedObjectKindReference: meta.NamespacedObjectKindReference{
-					Kind:      v1alpha1.ComponentVersionKind,
-					Name:      DefaultComponent.Name,
-					Namespace: DefaultComponent.Namespace,
-				},
-				ResourceRef: &v1alpha1.ResourceReference{
-					ElementMeta: v1alpha1.ElementMeta{
-						Name:    "introspect-image",
-						Version: "1.0.0",
+		Spec: v1alpha1.ConfigurationSpec{
+			MutationSpec: v1alpha1.MutationSpec{
+				Interval: metav1.Duration{},
+				ConfigRef: &v1alpha1.ObjectReference{
+					NamespacedObjectKindReference: meta.NamespacedObjectKindReference{
+						Kind:      v1alpha1.ComponentVersionKind,
+						Name:      DefaultComponent.Name,
+						Namespace: DefaultComponent.Namespace,
 					},
-					ReferencePath: []ocmmetav1.Identity{
-						{
-							"name": "test",
+					ResourceRef: &v1alpha1.ResourceReference{
+						ElementMeta: v1alpha1.ElementMeta{
+							Name:    "introspect-image",
+							Version: "1.0.0",
+						},
+						ReferencePath: []ocmmetav1.Identity{
+							{
+								"name": "test",
+							},
 						},
 					},
 				},
-			},
-			Values: &apiextensionsv1.JSON{
-				Raw: []byte(`{"message": "this is a new message", "color": "bittersweet"}`),
+				Values: &apiextensionsv1.JSON{
+					Raw: []byte(`{"message": "this is a new message", "color": "bittersweet"}`),
+				},
 			},
 		},
 	}
@@ -32,7 +32,7 @@ func TestHelmChartResource(t *testing.T) {
 	require.NoError(t, err)
 
 	cache := oci.NewClient("127.0.0.1:5000", oci.WithInsecureSkipVerify(true))
-	_, _, err = cache.PushData(context.Background(), charts, registry.ChartLayerMediaType, "podinfo", "6.3.5")
+	_, _, err = cache.PushData(context.Background(), charts, registry.ChartLayerMediaType, "podinfo", "6.3.5", nil)
 	require.NoError(t, err)
 
 	setupComponent := features.New("Add components to component-version").
@@ -26,6 +26,7 @@ import (
 
 	"github.com/open-component-model/ocm-controller/api/v1alpha1"
 	"github.com/open-component-model/ocm-controller/controllers"
+	"github.com/open-component-model/ocm-controller/pkg/access"
 	"github.com/open-component-model/ocm-controller/pkg/oci"
 	"github.com/open-component-model/ocm-controller/pkg/ocm"
 	"github.com/open-component-model/ocm-controller/pkg/snapshot"
@@ -57,6 +58,7 @@ func main() {
 		ociRegistryCertSecretName     string
 		ociRegistryInsecureSkipVerify bool
 		ociRegistryNamespace          string
+		registryMirrors               string
 	)
 
 	flag.StringVar(
@@ -96,6 +98,12 @@ func main() {
 		false,
 		"Skip verification of the certificate that the registry is using.",
 	)
+	flag.StringVar(
+		&registryMirrors,
+		"registry-mirrors",
+		"",
+		"Comma separated mirrors of registries for localizations, e.g. ghcr.io/org=mirror.internal/team-a.",
+	)
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -114,6 +122,12 @@ func main() {
 	// to set it both for controller-runtime ( i.e. zap )  and yqlib ( i.e. go-log )
 	glog.SetLevel(glog.WARNING, "yq-lib")
 
+	mirrors, err := access.ParseMirrors(registryMirrors)
+	if err != nil {
+		setupLog.Error(err, "invalid registry mirrors")
+		os.Exit(1)
+	}
+
 	restConfig := ctrl.GetConfigOrDie()
 	// Stop enabling client-side ratelimiter by default (https://github.com/kubernetes-sigs/controller-runtime/pull/3119)
 	// Previous behavior can be preserved by setting QPS 20 and Burst 30 on the rest.Config
@@ -138,7 +152,7 @@ func main() {
 		ociRegistryAddr = v
 	}
 
-	setupManagers(ociRegistryAddr, mgr, ociRegistryNamespace, ociRegistryCertSecretName, ociRegistryInsecureSkipVerify, restConfig, eventsAddr)
+	setupManagers(ociRegistryAddr, mgr, ociRegistryNamespace, ociRegistryCertSecretName, ociRegistryInsecureSkipVerify, restConfig, eventsAddr, mirrors)
 
 	//+kubebuilder:scaffold:builder
 
@@ -167,6 +181,7 @@ func setupManagers(
 	ociRegistryInsecureSkipVerify bool,
 	restConfig *rest.Config,
 	eventsAddr string,
+	mirrors []access.Mirror,
 ) {
 	cache := oci.NewClient(
 		ociRegistryAddr,
@@ -223,12 +238,13 @@ func setupManagers(
 	}
 
 	mutationReconciler := controllers.MutationReconcileLooper{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		OCMClient:      ocmClient,
-		DynamicClient:  dynClient,
-		Cache:          cache,
-		SnapshotWriter: snapshotWriter,
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		OCMClient:       ocmClient,
+		DynamicClient:   dynClient,
+		Cache:           cache,
+		SnapshotWriter:  snapshotWriter,
+		RegistryMirrors: mirrors,
 	}
 
 	if err = (&controllers.LocalizationReconciler{
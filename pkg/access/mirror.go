@@ -0,0 +1,103 @@
+package access
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHub         = "docker.io"
+	dockerHubRegistry = "index.docker.io"
+)
+
+// Mirror rewrites the locations of a registry, or of the repositories below a path of it, to a mirror, e.g.
+// ghcr.io/org to mirror.internal/team-a rewrites ghcr.io/org/podinfo to mirror.internal/team-a/podinfo.
+type Mirror struct {
+	// Source is a registry optionally followed by a path.
+	Source string
+	// Target is the registry optionally followed by a path the repositories of Source are mirrored to.
+	Target string
+}
+
+// ParseMirrors parses a comma separated list of mirrors written as source=target.
+func ParseMirrors(s string) ([]Mirror, error) {
+	var mirrors []Mirror
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		source, target, ok := strings.Cut(entry, "=")
+		if !ok || source == "" || target == "" {
+			return nil, fmt.Errorf("invalid mirror %s, expected source=target", entry)
+		}
+
+		mirrors = append(mirrors, Mirror{Source: source, Target: target})
+	}
+
+	return mirrors, nil
+}
+
+// Mirror rewrites the registry, repository and image of the location to the mirror whose source is the
+// longest prefix of its repository. If sources are equal, the first mirror is used. Tag and digest are kept.
+// The URL of OCI based helm repositories is rewritten as well. Locations without a registry, e.g. of S3
+// objects, aren't rewritten.
+func (l *Location) Mirror(mirrors []Mirror) {
+	if strings.HasPrefix(l.URL, ociScheme) {
+		if url, ok := mirrorReference(strings.TrimPrefix(l.URL, ociScheme), mirrors); ok {
+			l.URL = ociScheme + url
+		}
+	}
+
+	repository := l.FullyQualifiedRepository()
+	if repository == "" {
+		return
+	}
+
+	mirrored, ok := mirrorReference(repository, mirrors)
+	if !ok {
+		return
+	}
+
+	registry, path, _ := strings.Cut(mirrored, "/")
+	l.Registry = registry
+	l.Repository = path
+
+	if strings.HasPrefix(l.Image, repository) {
+		l.Image = mirrored + strings.TrimPrefix(l.Image, repository)
+	}
+}
+
+// mirrorReference replaces the longest source of the mirrors which is a prefix of the repository with its
+// target.
+func mirrorReference(repository string, mirrors []Mirror) (string, bool) {
+	var (
+		target string
+		source string
+	)
+
+	for _, m := range mirrors {
+		s := normalizeMirror(m.Source)
+		if (repository == s || strings.HasPrefix(repository, s+"/")) && len(s) > len(source) {
+			target, source = m.Target, s
+		}
+	}
+
+	if source == "" {
+		return "", false
+	}
+
+	return strings.TrimSuffix(target, "/") + strings.TrimPrefix(repository, source), true
+}
+
+// normalizeMirror writes Docker Hub sources with the registry locations of Docker Hub images have.
+func normalizeMirror(source string) string {
+	source = strings.TrimSuffix(source, "/")
+	if source == dockerHub || strings.HasPrefix(source, dockerHub+"/") {
+		return dockerHubRegistry + strings.TrimPrefix(source, dockerHub)
+	}
+
+	return source
+}
@@ -0,0 +1,103 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocation_Mirror(t *testing.T) {
+	mirrors := []Mirror{
+		{Source: "ghcr.io", Target: "mirror.internal/ghcr"},
+		{Source: "ghcr.io/stefanprodan", Target: "mirror.internal/team-a/"},
+		{Source: "ghcr.io/stefanprodan", Target: "mirror.internal/team-b"},
+		{Source: "docker.io/library", Target: "mirror.internal:5000"},
+	}
+
+	testCases := []struct {
+		name     string
+		ref      string
+		expected Location
+	}{
+		{
+			name: "longest source",
+			ref:  "ghcr.io/stefanprodan/podinfo:6.1.0",
+			expected: Location{
+				Registry: "mirror.internal", Repository: "team-a/podinfo", Image: "mirror.internal/team-a/podinfo:6.1.0", Tag: "6.1.0",
+			},
+		},
+		{
+			name: "registry",
+			ref:  "ghcr.io/org/backend@sha256:7f0168496f273c1e2095703a050128114d339c580b0906cd124a93b66ae471e2",
+			expected: Location{
+				Registry:   "mirror.internal",
+				Repository: "ghcr/org/backend",
+				Image:      "mirror.internal/ghcr/org/backend@sha256:7f0168496f273c1e2095703a050128114d339c580b0906cd124a93b66ae471e2",
+				Tag:        "sha256:7f0168496f273c1e2095703a050128114d339c580b0906cd124a93b66ae471e2",
+				Digest:     "sha256:7f0168496f273c1e2095703a050128114d339c580b0906cd124a93b66ae471e2",
+			},
+		},
+		{
+			name:     "docker hub",
+			ref:      "nginx:1.25",
+			expected: Location{Registry: "mirror.internal:5000", Repository: "nginx", Image: "mirror.internal:5000/nginx:1.25", Tag: "1.25"},
+		},
+		{
+			name:     "no mirror",
+			ref:      "quay.io/org/app:1.0.0",
+			expected: Location{Registry: "quay.io", Repository: "org/app", Image: "quay.io/org/app:1.0.0", Tag: "1.0.0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loc, err := ociLocation(tc.ref)
+			require.NoError(t, err)
+
+			loc.Mirror(mirrors)
+			assert.Equal(t, tc.expected, *loc)
+		})
+	}
+
+	helm := &Location{Registry: "ghcr.io", Repository: "stefanprodan/charts/podinfo", Tag: "6.1.0", URL: "oci://ghcr.io/stefanprodan/charts"}
+	helm.Mirror(mirrors)
+	assert.Equal(t, "oci://mirror.internal/team-a/charts", helm.URL)
+	assert.Equal(t, "mirror.internal/team-a/charts/podinfo", helm.FullyQualifiedRepository())
+
+	s3 := &Location{URL: "https://bucket.s3.amazonaws.com/deploy.yaml"}
+	s3.Mirror(mirrors)
+	assert.Equal(t, "https://bucket.s3.amazonaws.com/deploy.yaml", s3.URL)
+}
+
+func TestParseMirrors(t *testing.T) {
+	mirrors, err := ParseMirrors("ghcr.io=mirror.internal/ghcr, docker.io=mirror.internal/hub,")
+	require.NoError(t, err)
+	assert.Equal(t, []Mirror{
+		{Source: "ghcr.io", Target: "mirror.internal/ghcr"},
+		{Source: "docker.io", Target: "mirror.internal/hub"},
+	}, mirrors)
+
+	_, err = ParseMirrors("ghcr.io")
+	assert.ErrorContains(t, err, "invalid mirror ghcr.io, expected source=target")
+}
+
+func TestRegistry_ResolveMirrors(t *testing.T) {
+	opts := Options{
+		RepositoryURL: "ghcr.io/org/components",
+		Version:       "1.0.0",
+		Mirrors:       []Mirror{{Source: "ghcr.io/org", Target: "ghcr.io/org/mirror"}},
+	}
+
+	loc, err := NewRegistry().Resolve(map[string]any{
+		"type":          "localBlob",
+		"referenceName": "podinfo",
+		"globalAccess":  map[string]any{"type": "ociArtifact", "imageReference": "ghcr.io/org/podinfo:6.1.0"},
+	}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/org/mirror/podinfo:6.1.0", loc.Image, "mirrored once")
+
+	loc, err = NewRegistry().Resolve(map[string]any{"type": "localBlob", "referenceName": "podinfo"}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/org/mirror/components/podinfo:1.0.0", loc.Image)
+}
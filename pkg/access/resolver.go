@@ -0,0 +1,159 @@
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Location describes where the content of a resource can be found. Only the fields the access type of
+// the resource is able to provide are set.
+type Location struct {
+	// AccessType is the type of the access specification the location was resolved from.
+	AccessType string
+	Registry   string
+	Repository string
+	Image      string
+	Tag        string
+	Digest     string
+	URL        string
+}
+
+// FullyQualifiedRepository returns the registry and the repository joined together. It's empty if
+// either of them is not known.
+func (l *Location) FullyQualifiedRepository() string {
+	if l.Registry == "" || l.Repository == "" {
+		return ""
+	}
+
+	return l.Registry + "/" + l.Repository
+}
+
+// ImageWithDigest returns the image reference pinned by digest. It's empty if the digest is not known.
+func (l *Location) ImageWithDigest() string {
+	if l.Digest == "" {
+		return ""
+	}
+
+	repository := l.FullyQualifiedRepository()
+	if repository == "" {
+		return ""
+	}
+
+	return repository + "@" + l.Digest
+}
+
+// Options contains information about the resource which isn't part of its access specification.
+type Options struct {
+	// RepositoryURL is the URL of the OCI repository the component version is stored in.
+	RepositoryURL string
+	// Version is the version of the resource.
+	Version string
+	// Mirrors rewrite the resolved location, see Location.Mirror.
+	Mirrors []Mirror
+}
+
+// Resolver resolves the location of a resource from its access specification.
+type Resolver interface {
+	Resolve(spec map[string]any, opts Options) (*Location, error)
+}
+
+// ResolverFunc allows plain functions to be used as a Resolver.
+type ResolverFunc func(spec map[string]any, opts Options) (*Location, error)
+
+// Resolve calls f(spec, opts).
+func (f ResolverFunc) Resolve(spec map[string]any, opts Options) (*Location, error) {
+	return f(spec, opts)
+}
+
+// Registry contains the resolvers for each known access type.
+type Registry struct {
+	resolvers map[string]Resolver
+}
+
+// NewRegistry creates a Registry which knows how to resolve the common OCM access types.
+func NewRegistry() *Registry {
+	r := &Registry{
+		resolvers: make(map[string]Resolver),
+	}
+
+	r.Register(ResolverFunc(resolveOCIArtifact), OCIArtifactType, LegacyOCIArtifactType)
+	r.Register(ResolverFunc(resolveOCIBlob), OCIBlobType)
+	r.Register(ResolverFunc(r.resolveLocalBlob), LocalBlobType, LegacyLocalBlobType)
+	r.Register(ResolverFunc(resolveHelm), HelmType)
+	r.Register(ResolverFunc(resolveS3), S3Type, LegacyS3Type)
+	r.Register(ResolverFunc(resolveWget), WgetType)
+
+	return r
+}
+
+// Register adds a resolver for the given access types. Existing resolvers for these types are replaced.
+func (r *Registry) Register(resolver Resolver, accessTypes ...string) {
+	for _, t := range accessTypes {
+		r.resolvers[t] = resolver
+	}
+}
+
+// Resolve looks up the resolver of the type of the access specification and uses it to determine the
+// location of the resource.
+func (r *Registry) Resolve(spec map[string]any, opts Options) (*Location, error) {
+	accessType, ok := spec["type"].(string)
+	if !ok || accessType == "" {
+		return nil, errors.New("access specification has no type")
+	}
+
+	resolver, ok := r.resolvers[accessType]
+	if !ok {
+		// versioned types such as ociArtifact/v1 share the resolver of the unversioned type.
+		resolver, ok = r.resolvers[typeName(accessType)]
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("cannot determine access spec type %s", accessType)
+	}
+
+	loc, err := resolver.Resolve(spec, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s access: %w", accessType, err)
+	}
+
+	if loc.AccessType == "" {
+		loc.AccessType = accessType
+	}
+
+	loc.Mirror(opts.Mirrors)
+
+	return loc, nil
+}
+
+// ToMap converts any access specification into its generic representation.
+func ToMap(spec any) (map[string]any, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal access specification: %w", err)
+	}
+
+	result := make(map[string]any)
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal access specification: %w", err)
+	}
+
+	return result, nil
+}
+
+func typeName(accessType string) string {
+	name, _, _ := strings.Cut(accessType, "/")
+
+	return name
+}
+
+// decode converts the generic access specification into the given typed representation.
+func decode(spec map[string]any, out any) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,223 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Resolve(t *testing.T) {
+	const digest = "sha256:7f0168496f273c1e2095703a050128114d339c580b0906cd124a93b66ae471e2"
+
+	testCases := []struct {
+		name        string
+		spec        map[string]any
+		opts        Options
+		expected    *Location
+		expectedErr string
+	}{
+		{
+			name: "oci artifact",
+			spec: map[string]any{
+				"type":           "ociArtifact",
+				"imageReference": "ghcr.io/open-component-model/podinfo:6.3.5",
+			},
+			expected: &Location{
+				AccessType: "ociArtifact",
+				Registry:   "ghcr.io",
+				Repository: "open-component-model/podinfo",
+				Image:      "ghcr.io/open-component-model/podinfo:6.3.5",
+				Tag:        "6.3.5",
+			},
+		},
+		{
+			name: "oci artifact with tag and digest",
+			spec: map[string]any{
+				"type":           "ociArtifact",
+				"imageReference": "ghcr.io/open-component-model/podinfo:6.3.5@" + digest,
+			},
+			expected: &Location{
+				AccessType: "ociArtifact",
+				Registry:   "ghcr.io",
+				Repository: "open-component-model/podinfo",
+				Image:      "ghcr.io/open-component-model/podinfo@" + digest,
+				Tag:        "6.3.5",
+				Digest:     digest,
+			},
+		},
+		{
+			name: "versioned oci blob",
+			spec: map[string]any{
+				"type":   "ociBlob/v1",
+				"ref":    "ghcr.io/open-component-model/blob",
+				"digest": digest,
+			},
+			expected: &Location{
+				AccessType: "ociBlob/v1",
+				Registry:   "ghcr.io",
+				Repository: "open-component-model/blob",
+				Image:      "ghcr.io/open-component-model/blob@" + digest,
+				Tag:        digest,
+				Digest:     digest,
+			},
+		},
+		{
+			name: "local blob with global access",
+			spec: map[string]any{
+				"type": "localBlob",
+				"globalAccess": map[string]any{
+					"type":           "ociArtifact",
+					"imageReference": "ghcr.io/open-component-model/podinfo:6.3.5",
+				},
+			},
+			expected: &Location{
+				AccessType: "ociArtifact",
+				Registry:   "ghcr.io",
+				Repository: "open-component-model/podinfo",
+				Image:      "ghcr.io/open-component-model/podinfo:6.3.5",
+				Tag:        "6.3.5",
+			},
+		},
+		{
+			name: "local blob with reference name",
+			spec: map[string]any{
+				"type":          "localBlob",
+				"referenceName": "open-component-model/podinfo",
+			},
+			opts: Options{
+				RepositoryURL: "https://ghcr.io/components",
+				Version:       "6.3.5",
+			},
+			expected: &Location{
+				AccessType: "localBlob",
+				Registry:   "ghcr.io",
+				Repository: "components/open-component-model/podinfo",
+				Image:      "ghcr.io/components/open-component-model/podinfo:6.3.5",
+				Tag:        "6.3.5",
+			},
+		},
+		{
+			name: "local blob without reference name",
+			spec: map[string]any{
+				"type": "localBlob",
+			},
+			expectedErr: "local blob has neither a global access nor a reference name",
+		},
+		{
+			name: "oci helm repository",
+			spec: map[string]any{
+				"type":           "helm",
+				"helmRepository": "oci://ghcr.io/charts",
+				"helmChart":      "podinfo:6.3.5",
+			},
+			expected: &Location{
+				AccessType: "helm",
+				Registry:   "ghcr.io",
+				Repository: "charts/podinfo",
+				Image:      "ghcr.io/charts/podinfo:6.3.5",
+				Tag:        "6.3.5",
+				URL:        "oci://ghcr.io/charts",
+			},
+		},
+		{
+			name: "http helm repository",
+			spec: map[string]any{
+				"type":           "helm",
+				"helmRepository": "https://stefanprodan.github.io/podinfo",
+				"helmChart":      "podinfo",
+				"version":        "6.3.5",
+			},
+			expected: &Location{
+				AccessType: "helm",
+				Repository: "podinfo",
+				Tag:        "6.3.5",
+				URL:        "https://stefanprodan.github.io/podinfo",
+			},
+		},
+		{
+			name: "s3",
+			spec: map[string]any{
+				"type":   "s3",
+				"region": "eu-west-1",
+				"bucket": "ocm",
+				"key":    "manifests/deploy.yaml",
+			},
+			expected: &Location{
+				AccessType: "s3",
+				URL:        "https://ocm.s3.eu-west-1.amazonaws.com/manifests/deploy.yaml",
+			},
+		},
+		{
+			name: "s3 v2 with endpoint",
+			spec: map[string]any{
+				"type":        "S3/v2",
+				"bucketName":  "ocm",
+				"objectKey":   "manifests/deploy.yaml",
+				"awsEndpoint": "https://minio.local/",
+			},
+			expected: &Location{
+				AccessType: "S3/v2",
+				URL:        "https://minio.local/ocm/manifests/deploy.yaml",
+			},
+		},
+		{
+			name: "wget",
+			spec: map[string]any{
+				"type": "wget",
+				"url":  "https://example.com/deploy.yaml",
+			},
+			expected: &Location{
+				AccessType: "wget",
+				URL:        "https://example.com/deploy.yaml",
+			},
+		},
+		{
+			name: "unknown type",
+			spec: map[string]any{
+				"type": "gitHub",
+			},
+			expectedErr: "cannot determine access spec type gitHub",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loc, err := NewRegistry().Resolve(tc.spec, tc.opts)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, loc)
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	r.Register(ResolverFunc(func(spec map[string]any, _ Options) (*Location, error) {
+		return &Location{URL: spec["repoUrl"].(string)}, nil
+	}), "gitHub")
+
+	loc, err := r.Resolve(map[string]any{"type": "gitHub", "repoUrl": "https://github.com/open-component-model/ocm"}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/open-component-model/ocm", loc.URL)
+	assert.Equal(t, "gitHub", loc.AccessType)
+}
+
+func TestLocation_ImageWithDigest(t *testing.T) {
+	loc := &Location{
+		Registry:   "ghcr.io",
+		Repository: "open-component-model/podinfo",
+		Tag:        "6.3.5",
+	}
+	assert.Empty(t, loc.ImageWithDigest(), "no digest known")
+
+	loc.Digest = "sha256:7f0168496f273c1e2095703a050128114d339c580b0906cd124a93b66ae471e2"
+	assert.Equal(t, "ghcr.io/open-component-model/podinfo@"+loc.Digest, loc.ImageWithDigest())
+
+	assert.Empty(t, (&Location{URL: "https://example.com/deploy.yaml", Digest: loc.Digest}).ImageWithDigest(), "no repository known")
+}
@@ -0,0 +1,221 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Access types supported by the default registry.
+const (
+	OCIArtifactType       = "ociArtifact"
+	LegacyOCIArtifactType = "ociRegistry"
+	OCIBlobType           = "ociBlob"
+	LocalBlobType         = "localBlob"
+	LegacyLocalBlobType   = "localFilesystemBlob"
+	HelmType              = "helm"
+	S3Type                = "S3"
+	LegacyS3Type          = "s3"
+	WgetType              = "wget"
+)
+
+const ociScheme = "oci://"
+
+type ociArtifactSpec struct {
+	ImageReference string `json:"imageReference"`
+}
+
+type ociBlobSpec struct {
+	Reference string `json:"ref"`
+	Digest    string `json:"digest"`
+}
+
+type localBlobSpec struct {
+	ReferenceName string         `json:"referenceName,omitempty"`
+	GlobalAccess  map[string]any `json:"globalAccess,omitempty"`
+}
+
+type helmSpec struct {
+	HelmRepository string `json:"helmRepository"`
+	HelmChart      string `json:"helmChart"`
+	Version        string `json:"version,omitempty"`
+}
+
+type s3Spec struct {
+	Region      string `json:"region,omitempty"`
+	Bucket      string `json:"bucket,omitempty"`
+	Key         string `json:"key,omitempty"`
+	BucketName  string `json:"bucketName,omitempty"`
+	ObjectKey   string `json:"objectKey,omitempty"`
+	AWSEndpoint string `json:"awsEndpoint,omitempty"`
+}
+
+type wgetSpec struct {
+	URL string `json:"url"`
+}
+
+func resolveOCIArtifact(spec map[string]any, _ Options) (*Location, error) {
+	var s ociArtifactSpec
+	if err := decode(spec, &s); err != nil {
+		return nil, err
+	}
+
+	return ociLocation(s.ImageReference)
+}
+
+func resolveOCIBlob(spec map[string]any, _ Options) (*Location, error) {
+	var s ociBlobSpec
+	if err := decode(spec, &s); err != nil {
+		return nil, err
+	}
+
+	return ociLocation(fmt.Sprintf("%s@%s", s.Reference, s.Digest))
+}
+
+// resolveLocalBlob prefers the global access of a local blob. Without a global access the reference name
+// is used, which is the name the blob is stored as next to the component in its OCI repository.
+func (r *Registry) resolveLocalBlob(spec map[string]any, opts Options) (*Location, error) {
+	var s localBlobSpec
+	if err := decode(spec, &s); err != nil {
+		return nil, err
+	}
+
+	if s.GlobalAccess != nil {
+		// the location of the global access is mirrored once it's returned
+		global := opts
+		global.Mirrors = nil
+
+		return r.Resolve(s.GlobalAccess, global)
+	}
+
+	if s.ReferenceName == "" {
+		return nil, errors.New("local blob has neither a global access nor a reference name")
+	}
+
+	if opts.RepositoryURL == "" {
+		return nil, errors.New("repository url of the component is unknown")
+	}
+
+	base := opts.RepositoryURL
+	if u, err := url.Parse(base); err == nil && u.Host != "" {
+		base = u.Host + u.Path
+	}
+
+	ref := path.Join(base, s.ReferenceName)
+	if !strings.ContainsAny(s.ReferenceName, ":@") && opts.Version != "" {
+		ref += ":" + opts.Version
+	}
+
+	return ociLocation(ref)
+}
+
+// resolveHelm handles both OCI based and classic helm repositories. Only OCI based repositories can provide
+// a registry and an image.
+func resolveHelm(spec map[string]any, opts Options) (*Location, error) {
+	var s helmSpec
+	if err := decode(spec, &s); err != nil {
+		return nil, err
+	}
+
+	chart, version, _ := strings.Cut(s.HelmChart, ":")
+	if s.Version != "" {
+		version = s.Version
+	}
+
+	if version == "" {
+		version = opts.Version
+	}
+
+	if strings.HasPrefix(s.HelmRepository, ociScheme) {
+		ref := path.Join(strings.TrimPrefix(s.HelmRepository, ociScheme), chart)
+		if version != "" {
+			ref += ":" + version
+		}
+
+		loc, err := ociLocation(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		loc.URL = s.HelmRepository
+
+		return loc, nil
+	}
+
+	return &Location{
+		Repository: chart,
+		Tag:        version,
+		URL:        s.HelmRepository,
+	}, nil
+}
+
+// resolveS3 supports the bucket/key fields of the first version of the S3 access as well as the
+// bucketName/objectKey fields of the second version.
+func resolveS3(spec map[string]any, _ Options) (*Location, error) {
+	var s s3Spec
+	if err := decode(spec, &s); err != nil {
+		return nil, err
+	}
+
+	bucket, key := s.Bucket, s.Key
+	if s.BucketName != "" {
+		bucket, key = s.BucketName, s.ObjectKey
+	}
+
+	if bucket == "" || key == "" {
+		return nil, errors.New("bucket and key must be set")
+	}
+
+	if s.AWSEndpoint != "" {
+		return &Location{URL: strings.TrimSuffix(s.AWSEndpoint, "/") + "/" + path.Join(bucket, key)}, nil
+	}
+
+	host := bucket + ".s3.amazonaws.com"
+	if s.Region != "" {
+		host = fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, s.Region)
+	}
+
+	return &Location{URL: (&url.URL{Scheme: "https", Host: host, Path: "/" + key}).String()}, nil
+}
+
+func resolveWget(spec map[string]any, _ Options) (*Location, error) {
+	var s wgetSpec
+	if err := decode(spec, &s); err != nil {
+		return nil, err
+	}
+
+	if s.URL == "" {
+		return nil, errors.New("url must be set")
+	}
+
+	return &Location{URL: s.URL}, nil
+}
+
+func ociLocation(ref string) (*Location, error) {
+	pRef, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse access reference: %w", err)
+	}
+
+	loc := &Location{
+		Registry:   pRef.Context().Registry.Name(),
+		Repository: pRef.Context().RepositoryStr(),
+		Image:      pRef.Name(),
+		Tag:        pRef.Identifier(),
+	}
+
+	if digest, ok := pRef.(name.Digest); ok {
+		loc.Digest = digest.DigestStr()
+
+		// a reference like image:tag@digest keeps its tag, the digest is only the identifier without one
+		if tag, err := name.NewTag(strings.TrimSuffix(ref, "@"+loc.Digest), name.StrictValidation); err == nil {
+			loc.Tag = tag.TagStr()
+		}
+	}
+
+	return loc, nil
+}
@@ -51,13 +51,24 @@ func (f *FakeCache) IsCachedWasNotCalled() bool {
 	return len(f.isCachedCalledWith) == 0
 }
 
-func (f *FakeCache) PushData(ctx context.Context, data io.ReadCloser, mediaType, name, tag string) (string, int64, error) {
+func (f *FakeCache) PushData(
+	ctx context.Context,
+	data io.ReadCloser,
+	mediaType, name, tag string,
+	annotations map[string]string,
+) (string, int64, error) {
 	content, err := io.ReadAll(data)
 	if err != nil {
 		return "", -1, fmt.Errorf("failed to read read closer: %w", err)
 	}
 
-	f.pushDataCalledWith = append(f.pushDataCalledWith, PushDataArguments{Content: string(content), Name: name, Version: tag})
+	f.pushDataCalledWith = append(f.pushDataCalledWith, PushDataArguments{
+		Content:     string(content),
+		Name:        name,
+		Version:     tag,
+		MediaType:   mediaType,
+		Annotations: annotations,
+	})
 	return f.pushDataString, f.pushDataSize, f.pushDataErr
 }
 
@@ -67,9 +78,11 @@ func (f *FakeCache) PushDataReturns(digest string, err error) {
 }
 
 type PushDataArguments struct {
-	Name    string
-	Version string
-	Content string
+	Name        string
+	Version     string
+	Content     string
+	MediaType   string
+	Annotations map[string]string
 }
 
 func (f *FakeCache) PushDataCallingArgumentsOnCall(i int) PushDataArguments {
@@ -0,0 +1,94 @@
+package component
+
+import (
+	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+)
+
+// GetArtifactMetadata constructs the artifact metadata of a resource using the component descriptor
+// the resource belongs to. It returns nil if the resource cannot be found in the descriptor.
+func GetArtifactMetadata(cd *v1alpha1.ComponentDescriptor, ref *v1alpha1.ResourceReference) *v1alpha1.ArtifactMetadata {
+	if cd == nil || ref == nil {
+		return nil
+	}
+
+	resource := findResource(cd, ref.ElementMeta)
+	if resource == nil {
+		return nil
+	}
+
+	identity := ocmmetav1.Identity{
+		"name": resource.Name,
+	}
+	if resource.Version != "" {
+		identity["version"] = resource.Version
+	}
+
+	for k, v := range resource.ExtraIdentity {
+		identity[k] = v
+	}
+
+	metadata := &v1alpha1.ArtifactMetadata{
+		MediaType:        resourceMediaType(resource),
+		ResourceType:     resource.Type,
+		ResourceIdentity: identity,
+		ComponentName:    cd.Name,
+		ComponentVersion: cd.Spec.Version,
+	}
+
+	if resource.Digest != nil {
+		metadata.SourceDigest = resource.Digest.Value
+	}
+
+	return metadata
+}
+
+func findResource(cd *v1alpha1.ComponentDescriptor, meta v1alpha1.ElementMeta) *v3alpha1.Resource {
+	for i, r := range cd.Spec.Resources {
+		if r.Name != meta.Name {
+			continue
+		}
+
+		if meta.Version != "" && r.Version != meta.Version {
+			continue
+		}
+
+		if !matchesExtraIdentity(r.ExtraIdentity, meta.ExtraIdentity) {
+			continue
+		}
+
+		return &cd.Spec.Resources[i]
+	}
+
+	return nil
+}
+
+func matchesExtraIdentity(have, want ocmmetav1.Identity) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resourceMediaType returns the media type of the resource's content. Helm charts are stored as
+// OCI artifacts, but the controller always fetches the chart archive itself.
+func resourceMediaType(resource *v3alpha1.Resource) string {
+	if resource.Type == v1alpha1.HelmChartResourceType {
+		return v1alpha1.HelmChartMediaType
+	}
+
+	if resource.Access == nil {
+		return ""
+	}
+
+	if mediaType, ok := resource.Access.Object["mediaType"].(string); ok {
+		return mediaType
+	}
+
+	return ""
+}
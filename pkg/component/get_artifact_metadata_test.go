@@ -0,0 +1,113 @@
+package component
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
+	"ocm.software/ocm/api/ocm/compdesc/versions/ocm.software/v3alpha1"
+	ocmruntime "ocm.software/ocm/api/utils/runtime"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+)
+
+func TestGetArtifactMetadata(t *testing.T) {
+	cd := &v1alpha1.ComponentDescriptor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "github.com/skarlso/test",
+		},
+		Spec: v1alpha1.ComponentDescriptorSpec{
+			ComponentVersionSpec: v3alpha1.ComponentVersionSpec{
+				Resources: []v3alpha1.Resource{
+					{
+						ElementMeta: v3alpha1.ElementMeta{
+							Name:    "manifests",
+							Version: "1.0.0",
+							ExtraIdentity: ocmmetav1.Identity{
+								"platform": "linux",
+							},
+						},
+						Type: "blob",
+						Access: &ocmruntime.UnstructuredTypedObject{
+							Object: map[string]interface{}{
+								"type":      "localBlob",
+								"mediaType": "application/x-tar",
+							},
+						},
+						Digest: &ocmmetav1.DigestSpec{
+							Value: "abc",
+						},
+					},
+					{
+						ElementMeta: v3alpha1.ElementMeta{
+							Name:    "chart",
+							Version: "1.0.0",
+						},
+						Type: v1alpha1.HelmChartResourceType,
+					},
+				},
+			},
+			Version: "v0.1.0",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		ref      *v1alpha1.ResourceReference
+		expected *v1alpha1.ArtifactMetadata
+	}{
+		{
+			name: "resource with extra identity",
+			ref: &v1alpha1.ResourceReference{
+				ElementMeta: v1alpha1.ElementMeta{
+					Name:          "manifests",
+					ExtraIdentity: ocmmetav1.Identity{"platform": "linux"},
+				},
+			},
+			expected: &v1alpha1.ArtifactMetadata{
+				MediaType:    "application/x-tar",
+				ResourceType: "blob",
+				ResourceIdentity: ocmmetav1.Identity{
+					"name":     "manifests",
+					"version":  "1.0.0",
+					"platform": "linux",
+				},
+				ComponentName:    "github.com/skarlso/test",
+				ComponentVersion: "v0.1.0",
+				SourceDigest:     "abc",
+			},
+		},
+		{
+			name: "helm chart",
+			ref: &v1alpha1.ResourceReference{
+				ElementMeta: v1alpha1.ElementMeta{Name: "chart"},
+			},
+			expected: &v1alpha1.ArtifactMetadata{
+				MediaType:    v1alpha1.HelmChartMediaType,
+				ResourceType: v1alpha1.HelmChartResourceType,
+				ResourceIdentity: ocmmetav1.Identity{
+					"name":    "chart",
+					"version": "1.0.0",
+				},
+				ComponentName:    "github.com/skarlso/test",
+				ComponentVersion: "v0.1.0",
+			},
+		},
+		{
+			name: "extra identity does not match",
+			ref: &v1alpha1.ResourceReference{
+				ElementMeta: v1alpha1.ElementMeta{
+					Name:          "manifests",
+					ExtraIdentity: ocmmetav1.Identity{"platform": "windows"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GetArtifactMetadata(cd, tc.ref))
+		})
+	}
+}
@@ -74,12 +74,14 @@ func TestGetNestedComponentDescriptor(t *testing.T) {
 
 	t.Run("with reference path", func(t *testing.T) {
 		loc := &v1alpha1.Localization{
-			Spec: v1alpha1.MutationSpec{
-				ConfigRef: &v1alpha1.ObjectReference{
-					ResourceRef: &v1alpha1.ResourceReference{
-						ReferencePath: []ocmmetav1.Identity{
-							{
-								"name": "nested-twice-second",
+			Spec: v1alpha1.LocalizationSpec{
+				MutationSpec: v1alpha1.MutationSpec{
+					ConfigRef: &v1alpha1.ObjectReference{
+						ResourceRef: &v1alpha1.ResourceReference{
+							ReferencePath: []ocmmetav1.Identity{
+								{
+									"name": "nested-twice-second",
+								},
 							},
 						},
 					},
@@ -93,9 +95,11 @@ func TestGetNestedComponentDescriptor(t *testing.T) {
 
 	t.Run("without reference path", func(t *testing.T) {
 		loc := &v1alpha1.Localization{
-			Spec: v1alpha1.MutationSpec{
-				ConfigRef: &v1alpha1.ObjectReference{
-					ResourceRef: &v1alpha1.ResourceReference{},
+			Spec: v1alpha1.LocalizationSpec{
+				MutationSpec: v1alpha1.MutationSpec{
+					ConfigRef: &v1alpha1.ObjectReference{
+						ResourceRef: &v1alpha1.ResourceReference{},
+					},
 				},
 			},
 		}
@@ -1,12 +1,14 @@
 package configdata
 
 import (
-	"github.com/xeipuuv/gojsonschema"
+	"encoding/json"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ConfigData defines configuration options.
-// This data is not promoted to being a CRD, but it contains versionable properties.
+// This data is not promoted to being a CRD, but it contains versionable properties. Use Parse to read it, it
+// checks the kind and version and rejects unknown fields.
 // The following is an example structure of this data:
 /*
 apiVersion: config.ocm.software/v1alpha1
@@ -20,6 +22,12 @@ configuration:
   - value: (( replicas ))
     file: helm_release.yaml
     path: spec.values.replicaCount
+  - value: (( replicas ))
+    file: deploy-*.yaml
+    document:
+      kind: Deployment
+      name: backend
+    path: spec.replicas
   schema:
     type: object
     additionalProperties: false
@@ -31,6 +39,14 @@ localization:
   tag: spec.chart.spec.version
   resource:
     name: chart
+- file: deployment.yaml
+  image: spec.template.spec.containers[0].image
+  resource:
+    name: image
+    extraIdentity:
+      architecture: amd64
+    referencePath:
+    - name: backend
 - file: helm_repository.yaml
   mapping:
     path: spec.url
@@ -65,43 +81,145 @@ localization:
 // - **image**
 // - **repository**
 // - **registry**
-// - **tag**.
+// - **tag**
+// - **digest**
+// - **imageWithDigest**, the image reference pinned by digest, e.g. ghcr.io/org/image@sha256:...
+// - **url**, for resources which aren't stored in an OCI registry, like S3 or HTTP blobs.
+// The file of a rule may be a glob pattern, ** matches any number of directories. The document selector
+// restricts a rule to the documents of a multi-document YAML file with the given kind and name.
+// Besides YAML, rules can target JSON, TOML, .env and properties files. The format is derived from the
+// file extension and can be set explicitly with the format field of a rule, one of yaml, json, toml, env
+// or properties. For .env and properties files the path is the name of the variable or property.
+// Only the properties the access type of the resource is able to provide can be used.
+// A rule with a when condition is only applied if the condition is true. Conditions are CUE expressions with
+// the values of the Configuration in scope as values, the component descriptor of the config as component and
+// the source as source, with the fields componentName, componentVersion, resourceName and resourceVersion.
+// For example, values.tracing != _|_ is true if the tracing value is set.
+// With autoLocalization, the images of the manifests are localized without rules, see AutoLocalization.
+// If preferDigest is set, every image is substituted with its digest pinned reference instead of its tag.
+// Digests are taken from the access of the resource or, if it doesn't contain one, fetched from the registry.
 type ConfigData struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	Configuration     ConfigurationSpec  `json:"configuration,omitempty"`
 	Localization      []LocalizationRule `json:"localization,omitempty"`
+	PreferDigest      bool               `json:"preferDigest,omitempty"`
+	AutoLocalization  *AutoLocalization  `json:"autoLocalization,omitempty"`
 }
 
+// Engines evaluating the values of configuration rules.
+const (
+	// EngineSpiff evaluates spiff expressions, e.g. (( replicas )). It's the default engine.
+	EngineSpiff = "spiff"
+	// EngineCUE evaluates CUE expressions, e.g. replicas * 2. The schema may be written in CUE.
+	EngineCUE = "cue"
+	// EngineGoTemplate renders Go templates, e.g. {{ .replicas }}.
+	EngineGoTemplate = "gotemplate"
+)
+
+// ConfigurationSpec defines the values of a configuration and how they are applied. HelmValues is a values
+// overlay for helm chart resources. Like the value of a rule, it may refer to the defaults and values with
+// expressions of the engine, e.g. replicaCount: (( replicas )). The result is merged into the values.yaml of
+// the chart before the rules are applied.
+// Schema is either a JSON schema the values are validated against, or, with the cue engine, a string holding
+// a CUE schema the defaults and values are unified with.
+// Profiles layer defaults, rules and helm values over the configuration, e.g. for an environment.
 type ConfigurationSpec struct {
-	Defaults map[string]any      `json:"defaults"`
-	Schema   gojsonschema.Schema `json:"schema"`
-	Rules    []ConfigRule        `json:"rules"`
+	Engine     string          `json:"engine,omitempty"`
+	Defaults   map[string]any  `json:"defaults"`
+	Schema     json.RawMessage `json:"schema,omitempty"`
+	Rules      []ConfigRule    `json:"rules"`
+	HelmValues map[string]any  `json:"helmValues,omitempty"`
+	Profiles   []Profile       `json:"profiles,omitempty"`
+}
+
+// Profile is a named layer over a configuration. Its defaults and helm values are deep merged over the ones
+// of the configuration and its rules are applied after the rules of the configuration.
+type Profile struct {
+	Name       string         `json:"name"`
+	Defaults   map[string]any `json:"defaults,omitempty"`
+	Rules      []ConfigRule   `json:"rules,omitempty"`
+	HelmValues map[string]any `json:"helmValues,omitempty"`
 }
 
 type ConfigRule struct {
-	Value any    `json:"value"`
-	Path  string `json:"path"`
-	File  string `json:"file"`
+	When     string            `json:"when,omitempty"`
+	Value    any               `json:"value"`
+	Path     string            `json:"path"`
+	File     string            `json:"file"`
+	Document *DocumentSelector `json:"document,omitempty"`
+	Format   string            `json:"format,omitempty"`
 }
 
 type LocalizationRule struct {
-	Resource                 ResourceItem `json:"resource"`
-	File                     string       `json:"file"`
-	Registry                 string       `json:"registry,omitempty"`
-	Mapping                  *Mapping     `json:"mapping,omitempty"`
-	Repository               string       `json:"repository,omitempty"`
-	FullyQualifiedRepository string       `json:"fullyQualifiedRepository,omitempty"`
-	Image                    string       `json:"image,omitempty"`
-	Tag                      string       `json:"tag,omitempty"`
+	When                     string            `json:"when,omitempty"`
+	Resource                 ResourceItem      `json:"resource"`
+	File                     string            `json:"file"`
+	Document                 *DocumentSelector `json:"document,omitempty"`
+	Format                   string            `json:"format,omitempty"`
+	Registry                 string            `json:"registry,omitempty"`
+	Mapping                  *Mapping          `json:"mapping,omitempty"`
+	Repository               string            `json:"repository,omitempty"`
+	FullyQualifiedRepository string            `json:"fullyQualifiedRepository,omitempty"`
+	Image                    string            `json:"image,omitempty"`
+	Tag                      string            `json:"tag,omitempty"`
+	Digest                   string            `json:"digest,omitempty"`
+	ImageWithDigest          string            `json:"imageWithDigest,omitempty"`
+	URL                      string            `json:"url,omitempty"`
+}
+
+// DefaultImageLabel is the label of ociImage resources holding the image the manifests refer to them with.
+const DefaultImageLabel = "delivery.ocm.software/image"
+
+// AutoLocalization localizes the images referenced by the manifests matching File, every file by default.
+// Images are found in the containers, init containers and ephemeral containers of pod specs, e.g. of
+// Deployments and CronJobs, in the image fields of well-known custom resources like Prometheus and in Fields.
+// An image is replaced with the location of the ociImage resource of the component version whose Label,
+// delivery.ocm.software/image by default, holds the repository of the image, e.g. ghcr.io/org/podinfo. Without
+// such a label, the resource whose repository has the same name as the repository of the image, e.g. podinfo,
+// is used. Localization rules are applied after the images are localized.
+type AutoLocalization struct {
+	File   string       `json:"file,omitempty"`
+	Label  string       `json:"label,omitempty"`
+	Fields []ImageField `json:"fields,omitempty"`
 }
 
+// ImageField is the path of an image in documents of the given kind, e.g. spec.sidecars[*].image. [*]
+// selects every element of a list.
+type ImageField struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+}
+
+// DocumentSelector selects documents of a multi-document YAML file. Empty fields match any document.
+type DocumentSelector struct {
+	Kind string `json:"kind,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// Mapping substitutes the results of a CUE program. The out field of the program, a JSON encoded string, is
+// substituted at Path. Outputs substitute further fields of the program, which don't need to be encoded.
+// Besides the component, the program sees the repository URL of the component version, the namespace of the
+// localization, the parameters of the localization and the resources of the component version with their
+// resolved access.
 type Mapping struct {
-	Path      string `json:"path"`
-	Transform string `json:"transform"`
+	Path      string          `json:"path,omitempty"`
+	Transform string          `json:"transform"`
+	Outputs   []MappingOutput `json:"outputs,omitempty"`
+}
+
+// MappingOutput substitutes the value of Field, a path in the CUE program like images.frontend, at Path.
+type MappingOutput struct {
+	Field string `json:"field"`
+	Path  string `json:"path"`
 }
 
+// ResourceItem identifies the resource a localization rule applies to. By default, the resource is looked up
+// in the component version the ConfigData belongs to, ReferencePath allows pointing at resources of
+// referenced components instead.
 type ResourceItem struct {
-	Name          string            `json:"name"`
-	ExtraIdentity map[string]string `json:"extraIdentity,omitempty"`
+	Name          string              `json:"name"`
+	Version       string              `json:"version,omitempty"`
+	ExtraIdentity map[string]string   `json:"extraIdentity,omitempty"`
+	ReferencePath []map[string]string `json:"referencePath,omitempty"`
 }
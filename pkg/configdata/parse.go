@@ -0,0 +1,327 @@
+package configdata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	kjson "sigs.k8s.io/json"
+	"sigs.k8s.io/yaml"
+)
+
+// The versions of the ConfigData format.
+const (
+	GroupName = "config.ocm.software"
+	Kind      = "ConfigData"
+	V1Alpha1  = GroupName + "/v1alpha1"
+)
+
+// ErrInvalid is returned for config data which can't be parsed or fails validation.
+var ErrInvalid = errors.New("invalid config data")
+
+// Version is a version of the ConfigData format. Every version converts itself to ConfigData, the version
+// the controller works with. A new version, e.g. v1beta1, is added as its own type, registered in versions
+// and converted, so config data written against older versions keeps working unchanged.
+type Version interface {
+	ConvertTo(hub *ConfigData) error
+}
+
+// versions are the supported versions of the ConfigData format by their apiVersion.
+var versions = map[string]func() Version{
+	V1Alpha1: func() Version { return &ConfigData{} },
+}
+
+// ConvertTo implements Version. ConfigData is the hub, so it's copied as it is.
+func (c *ConfigData) ConvertTo(hub *ConfigData) error {
+	*hub = *c
+
+	return nil
+}
+
+// ParseError is an error in the config data read from File, at Line if it's known.
+type ParseError struct {
+	File    string
+	Line    int
+	Field   string
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+	}
+
+	if e.Field != "" {
+		return fmt.Sprintf("%s: %s: %s", location, e.Field, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// Parse strictly parses and validates the config data read from file. The kind must be ConfigData and the
+// apiVersion a supported version, config data without an apiVersion is parsed as v1alpha1. Unknown and
+// duplicate fields are rejected. Every error is reported with the file and, if known, the line, and wraps
+// ErrInvalid. Config data of other versions is converted to ConfigData.
+func Parse(file string, data []byte) (*ConfigData, error) {
+	root := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(data, root); err != nil {
+		return nil, invalid(&ParseError{File: file, Message: err.Error()})
+	}
+
+	doc := root
+	if doc.Kind == yamlv3.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+
+	if doc.Kind != yamlv3.MappingNode {
+		return nil, invalid(&ParseError{File: file, Line: max(doc.Line, 1), Message: "expected a ConfigData object"})
+	}
+
+	p := &parser{file: file, root: doc}
+
+	if errs := p.duplicateKeys(doc, ""); len(errs) > 0 {
+		return nil, invalid(errs...)
+	}
+
+	version, err := p.version()
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	content, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, invalid(&ParseError{File: file, Message: err.Error()})
+	}
+
+	if err := json.Unmarshal(content, version); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, invalid(p.fieldError(indexPath(typeErr.Field), fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)))
+		}
+
+		return nil, invalid(&ParseError{File: file, Message: err.Error()})
+	}
+
+	// the strict checks are done on a copy, the values of untyped fields are decoded like above
+	strictErrs, err := kjson.UnmarshalStrict(content, p.newVersion(), kjson.DisallowUnknownFields)
+	if err != nil {
+		return nil, invalid(&ParseError{File: file, Message: err.Error()})
+	}
+
+	var errs []error
+	for _, strictErr := range strictErrs {
+		var fieldErr kjson.FieldError
+		if errors.As(strictErr, &fieldErr) {
+			errs = append(errs, p.fieldError(fieldErr.FieldPath(), "unknown field"))
+
+			continue
+		}
+
+		errs = append(errs, &ParseError{File: file, Message: strictErr.Error()})
+	}
+
+	if len(errs) > 0 {
+		return nil, invalid(errs...)
+	}
+
+	config := &ConfigData{}
+	if err := version.ConvertTo(config); err != nil {
+		return nil, invalid(&ParseError{File: file, Message: fmt.Sprintf("failed to convert to %s: %s", V1Alpha1, err)})
+	}
+
+	for _, violation := range config.validate() {
+		errs = append(errs, p.fieldError(violation.path, violation.message))
+	}
+
+	if len(errs) > 0 {
+		return nil, invalid(errs...)
+	}
+
+	config.APIVersion = V1Alpha1
+
+	return config, nil
+}
+
+func invalid(errs ...error) error {
+	return fmt.Errorf("%w: %w", ErrInvalid, errors.Join(errs...))
+}
+
+// parser locates the fields of the config data to report the lines of errors.
+type parser struct {
+	file       string
+	root       *yamlv3.Node
+	apiVersion string
+}
+
+// version checks the kind and apiVersion and returns an empty value of the version.
+func (p *parser) version() (Version, error) {
+	kind := p.value("kind")
+	if kind == nil || kind.Value != Kind {
+		return nil, p.fieldError("kind", fmt.Sprintf("expected %s", Kind))
+	}
+
+	p.apiVersion = V1Alpha1
+	if apiVersion := p.value("apiVersion"); apiVersion != nil {
+		p.apiVersion = apiVersion.Value
+	}
+
+	if _, ok := versions[p.apiVersion]; !ok {
+		supported := make([]string, 0, len(versions))
+		for v := range versions {
+			supported = append(supported, v)
+		}
+
+		slices.Sort(supported)
+
+		message := fmt.Sprintf("unsupported version %s, supported versions are %s", p.apiVersion, strings.Join(supported, ", "))
+
+		return nil, p.fieldError("apiVersion", message)
+	}
+
+	return p.newVersion(), nil
+}
+
+func (p *parser) newVersion() Version {
+	return versions[p.apiVersion]()
+}
+
+// duplicateKeys reports keys which are set more than once in a mapping.
+func (p *parser) duplicateKeys(node *yamlv3.Node, path string) []error {
+	var errs []error
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			field := joinPath(path, key.Value)
+
+			if seen[key.Value] {
+				errs = append(errs, &ParseError{File: p.file, Line: key.Line, Field: field, Message: "duplicate field"})
+			}
+
+			seen[key.Value] = true
+			errs = append(errs, p.duplicateKeys(node.Content[i+1], field)...)
+		}
+	case yamlv3.SequenceNode:
+		for i, item := range node.Content {
+			errs = append(errs, p.duplicateKeys(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return errs
+}
+
+// fieldError returns an error for the field at path, e.g. configuration.rules[0].file. The line is the one of
+// the field or, if it doesn't exist, of its closest parent.
+func (p *parser) fieldError(path, message string) *ParseError {
+	line := 0
+	for segments := strings.Split(path, "."); len(segments) > 0; segments = segments[:len(segments)-1] {
+		if node := lookup(p.root, segments); node != nil {
+			line = node.Line
+
+			break
+		}
+	}
+
+	return &ParseError{File: p.file, Line: line, Field: path, Message: message}
+}
+
+// value returns the value of the top-level field with the name.
+func (p *parser) value(name string) *yamlv3.Node {
+	for i := 0; i+1 < len(p.root.Content); i += 2 {
+		if p.root.Content[i].Value == name {
+			return p.root.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+var segmentPattern = regexp.MustCompile(`^([^\[]*)((?:\[\d+\])*)$`)
+
+// lookup returns the node of the field at the path, the key for fields of mappings. Segments without an index
+// into a list, as in the paths of type errors, match the first item of the list the rest of the path exists in.
+func lookup(node *yamlv3.Node, segments []string) *yamlv3.Node {
+	if len(segments) == 0 {
+		return node
+	}
+
+	match := segmentPattern.FindStringSubmatch(segments[0])
+	if match == nil {
+		return nil
+	}
+
+	if node.Kind == yamlv3.SequenceNode {
+		for _, item := range node.Content {
+			if found := lookup(item, segments); found != nil {
+				return found
+			}
+		}
+
+		return nil
+	}
+
+	if node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != match[1] {
+			continue
+		}
+
+		key, value := node.Content[i], node.Content[i+1]
+		for _, index := range strings.Split(match[2], "]") {
+			if index == "" {
+				continue
+			}
+
+			n, _ := strconv.Atoi(strings.TrimPrefix(index, "["))
+			if value.Kind != yamlv3.SequenceNode || n >= len(value.Content) {
+				return nil
+			}
+
+			key, value = value.Content[n], value.Content[n]
+		}
+
+		if len(segments) == 1 {
+			return key
+		}
+
+		return lookup(value, segments[1:])
+	}
+
+	return nil
+}
+
+// indexPath writes the indices into lists of the paths of type errors, e.g. localization.0.file, like the
+// paths of strict errors, localization[0].file.
+func indexPath(path string) string {
+	var segments []string
+	for _, segment := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(segment); err == nil && len(segments) > 0 {
+			segments[len(segments)-1] += "[" + segment + "]"
+
+			continue
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return strings.Join(segments, ".")
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
@@ -0,0 +1,159 @@
+package configdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	config, err := Parse("config.yaml", []byte(`apiVersion: config.ocm.software/v1alpha1
+kind: ConfigData
+metadata:
+  name: ocm-config
+configuration:
+  defaults:
+    replicas: 1
+  rules:
+  - value: (( replicas ))
+    file: deploy.yaml
+    path: spec.replicas
+localization:
+- file: deploy.yaml
+  image: spec.template.spec.containers[0].image
+  resource:
+    name: image
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "ocm-config", config.Name)
+	assert.Equal(t, map[string]any{"replicas": float64(1)}, config.Configuration.Defaults)
+	assert.Len(t, config.Configuration.Rules, 1)
+	assert.Len(t, config.Localization, 1)
+
+	config, err = Parse("config.yaml", []byte("kind: ConfigData\nlocalization: []\n"))
+	require.NoError(t, err)
+	assert.Equal(t, V1Alpha1, config.APIVersion)
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   string
+		errors []string
+	}{
+		{
+			name:   "not an object",
+			data:   "iaminvalidyaml",
+			errors: []string{"config.yaml:1: expected a ConfigData object"},
+		},
+		{
+			name:   "syntax error",
+			data:   "kind: ConfigData\nconfiguration:\n  rules: [\n",
+			errors: []string{"config.yaml: yaml: line 3"},
+		},
+		{
+			name:   "unknown kind",
+			data:   "apiVersion: config.ocm.software/v1alpha1\nkind: Config\n",
+			errors: []string{"config.yaml:2: kind: expected ConfigData"},
+		},
+		{
+			name: "unknown version",
+			data: "apiVersion: config.ocm.software/v2\nkind: ConfigData\n",
+			errors: []string{
+				"config.yaml:1: apiVersion: unsupported version config.ocm.software/v2, supported versions are config.ocm.software/v1alpha1",
+			},
+		},
+		{
+			name: "unknown fields",
+			data: `kind: ConfigData
+configuration:
+  rules:
+  - value: 1
+    file: deploy.yaml
+    path: spec.replicas
+  - value: 2
+    file: deploy.yaml
+    paths: spec.replicas
+localisation: []
+`,
+			errors: []string{
+				"config.yaml:9: configuration.rules[1].paths: unknown field",
+				"config.yaml:10: localisation: unknown field",
+			},
+		},
+		{
+			name: "duplicate fields",
+			data: `kind: ConfigData
+localization:
+- file: deploy.yaml
+  image: spec.image
+  image: spec.template.spec.containers[0].image
+`,
+			errors: []string{"config.yaml:5: localization[0].image: duplicate field"},
+		},
+		{
+			name: "wrong type",
+			data: `kind: ConfigData
+localization:
+- file: deploy.yaml
+  resource:
+    name: image
+    extraIdentity: amd64
+`,
+			errors: []string{"config.yaml:6: localization[0].resource.extraIdentity: expected map[string]string, got string"},
+		},
+		{
+			name: "invalid rules",
+			data: `kind: ConfigData
+configuration:
+  engine: jsonnet
+  rules:
+  - value: 1
+    file: deploy.yaml
+  profiles:
+  - name: prod
+  - name: prod
+localization:
+- file: deploy.yaml
+  resource:
+    name: image
+`,
+			errors: []string{
+				"config.yaml:3: configuration.engine: unknown engine jsonnet",
+				"config.yaml:5: configuration.rules[0].path: required",
+				"config.yaml:9: configuration.profiles[1].name: duplicate profile prod",
+				"config.yaml:11: localization[0]: one of image",
+			},
+		},
+		{
+			name: "invalid mappings",
+			data: `kind: ConfigData
+localization:
+- file: deploy.yaml
+  mapping:
+    transform: 'out: "1"'
+- file: deploy.yaml
+  mapping:
+    transform: 'image: "podinfo"'
+    outputs:
+    - field: image
+`,
+			errors: []string{
+				"config.yaml:4: localization[0].mapping: one of path or outputs is required",
+				"config.yaml:10: localization[1].mapping.outputs[0].path: required",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse("config.yaml", []byte(tc.data))
+			require.ErrorIs(t, err, ErrInvalid)
+
+			for _, expected := range tc.errors {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package configdata
+
+import (
+	"fmt"
+	"slices"
+)
+
+// violation is a field of the config data with an invalid value.
+type violation struct {
+	path    string
+	message string
+}
+
+var (
+	engines = []string{"", EngineSpiff, EngineCUE, EngineGoTemplate}
+	formats = []string{"", "yaml", "json", "toml", "env", "properties"}
+)
+
+// validate checks the config data for errors which would otherwise only fail once the rules are applied.
+func (c *ConfigData) validate() []violation {
+	var violations []violation
+
+	add := func(path, format string, args ...any) {
+		violations = append(violations, violation{path: path, message: fmt.Sprintf(format, args...)})
+	}
+
+	if !slices.Contains(engines, c.Configuration.Engine) {
+		add("configuration.engine", "unknown engine %s, expected one of %s, %s or %s", c.Configuration.Engine, EngineSpiff, EngineCUE, EngineGoTemplate)
+	}
+
+	validateRules := func(path string, rules []ConfigRule) {
+		for i, rule := range rules {
+			rulePath := fmt.Sprintf("%s[%d]", path, i)
+			if rule.File == "" {
+				add(rulePath+".file", "required")
+			}
+
+			if rule.Path == "" {
+				add(rulePath+".path", "required")
+			}
+
+			if !slices.Contains(formats, rule.Format) {
+				add(rulePath+".format", "unknown format %s", rule.Format)
+			}
+		}
+	}
+
+	validateRules("configuration.rules", c.Configuration.Rules)
+
+	names := make(map[string]bool)
+	for i, profile := range c.Configuration.Profiles {
+		path := fmt.Sprintf("configuration.profiles[%d]", i)
+
+		switch {
+		case profile.Name == "":
+			add(path+".name", "required")
+		case names[profile.Name]:
+			add(path+".name", "duplicate profile %s", profile.Name)
+		}
+
+		names[profile.Name] = true
+		validateRules(path+".rules", profile.Rules)
+	}
+
+	for i, rule := range c.Localization {
+		path := fmt.Sprintf("localization[%d]", i)
+		if rule.File == "" {
+			add(path+".file", "required")
+		}
+
+		if !slices.Contains(formats, rule.Format) {
+			add(path+".format", "unknown format %s", rule.Format)
+		}
+
+		if rule.Mapping != nil {
+			if rule.Mapping.Path == "" && len(rule.Mapping.Outputs) == 0 {
+				add(path+".mapping", "one of path or outputs is required")
+			}
+
+			for j, output := range rule.Mapping.Outputs {
+				outputPath := fmt.Sprintf("%s.mapping.outputs[%d]", path, j)
+				if output.Field == "" {
+					add(outputPath+".field", "required")
+				}
+
+				if output.Path == "" {
+					add(outputPath+".path", "required")
+				}
+			}
+
+			continue
+		}
+
+		if rule.Resource.Name == "" {
+			add(path+".resource.name", "required")
+		}
+
+		if rule.Image == "" && rule.ImageWithDigest == "" && rule.Registry == "" && rule.Repository == "" &&
+			rule.FullyQualifiedRepository == "" && rule.Tag == "" && rule.Digest == "" && rule.URL == "" {
+			add(path, "one of image, imageWithDigest, registry, repository, fullyQualifiedRepository, tag, digest, url or mapping is required")
+		}
+	}
+
+	if auto := c.AutoLocalization; auto != nil {
+		for i, field := range auto.Fields {
+			path := fmt.Sprintf("autoLocalization.fields[%d]", i)
+			if field.Kind == "" {
+				add(path+".kind", "required")
+			}
+
+			if field.Path == "" {
+				add(path+".path", "required")
+			}
+		}
+	}
+
+	return violations
+}
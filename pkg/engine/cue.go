@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+)
+
+// CUE evaluates values as CUE expressions. The values are in scope, so a rule value like replicas * 2 or
+// "\(name)-svc" may refer to them. Literal strings need to be quoted.
+type CUE struct {
+	ctx  *cue.Context
+	root cue.Value
+}
+
+var _ Evaluator = &CUE{}
+
+// NewCUE unifies the values with the schema, written in CUE, and validates that the result is concrete.
+// Every violation is reported with the path of its field.
+func NewCUE(values map[string]any, schema string) (*CUE, error) {
+	ctx := cuecontext.New()
+	root := ctx.Encode(values)
+
+	if schema != "" {
+		s := ctx.CompileString(schema, cue.Filename("schema.cue"))
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+
+		root = s.Unify(root)
+	}
+
+	if err := root.Validate(cue.Concrete(true)); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", fieldErrors(err))
+	}
+
+	return &CUE{ctx: ctx, root: root}, nil
+}
+
+// Evaluate implements Evaluator.
+func (c *CUE) Evaluate(value json.RawMessage) (json.RawMessage, error) {
+	return evaluate(value, func(expression string) (any, error) {
+		v := c.ctx.CompileString(expression, cue.Scope(c.root), cue.InferBuiltins(true))
+		if err := v.Validate(cue.Concrete(true)); err != nil {
+			return nil, fmt.Errorf("failed to evaluate %q: %w", expression, fieldErrors(err))
+		}
+
+		var result any
+		if err := v.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode result of %q: %w", expression, err)
+		}
+
+		return result, nil
+	})
+}
+
+// fieldErrors prefixes the errors of CUE with the paths of their fields.
+func fieldErrors(err error) error {
+	var errs []error
+	for _, e := range cueerrors.Errors(err) {
+		format, args := e.Msg()
+		message := fmt.Sprintf(format, args...)
+
+		if path := e.Path(); len(path) > 0 {
+			message = strings.Join(path, ".") + ": " + message
+		}
+
+		errs = append(errs, errors.New(message))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Condition evaluates the conditions of rules, CUE expressions resulting in a boolean.
+type Condition struct {
+	ctx  *cue.Context
+	root cue.Value
+}
+
+// NewCondition returns a condition evaluator with the fields of the scope in scope.
+func NewCondition(scope map[string]any) (*Condition, error) {
+	ctx := cuecontext.New()
+
+	root := ctx.Encode(scope)
+	if err := root.Err(); err != nil {
+		return nil, fmt.Errorf("failed to encode scope: %w", err)
+	}
+
+	return &Condition{ctx: ctx, root: root}, nil
+}
+
+// Evaluate returns the result of the condition. Referring to a field which doesn't exist is an error, use
+// field != _|_ to check whether a field exists.
+func (c *Condition) Evaluate(expression string) (bool, error) {
+	v := c.ctx.CompileString(expression, cue.Scope(c.root), cue.InferBuiltins(true))
+
+	result, err := v.Bool()
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate %q: %w", expression, fieldErrors(err))
+	}
+
+	return result, nil
+}
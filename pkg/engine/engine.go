@@ -0,0 +1,56 @@
+// Package engine evaluates the values of configuration rules with CUE or Go templates. Both engines see the
+// defaults of a ConfigData merged with the values of a Configuration.
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Evaluator evaluates the value of a configuration rule.
+type Evaluator interface {
+	Evaluate(value json.RawMessage) (json.RawMessage, error)
+}
+
+// evaluate replaces the strings of the value with their result. Strings nested in maps and lists are
+// evaluated as well.
+func evaluate(value json.RawMessage, eval func(s string) (any, error)) (json.RawMessage, error) {
+	var data any
+	if err := json.Unmarshal(value, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+
+	result, err := walk(data, eval)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(result)
+}
+
+func walk(value any, eval func(s string) (any, error)) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return eval(v)
+	case map[string]any:
+		for key, item := range v {
+			result, err := walk(item, eval)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+
+			v[key] = result
+		}
+	case []any:
+		for i, item := range v {
+			result, err := walk(item, eval)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+
+			v[i] = result
+		}
+	}
+
+	return value, nil
+}
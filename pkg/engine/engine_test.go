@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testValues = map[string]any{
+	"name":     "podinfo",
+	"replicas": 2,
+	"features": map[string]any{"metrics": true},
+	"hosts":    []any{"a.example.com", "b.example.com"},
+}
+
+func TestCUE(t *testing.T) {
+	c, err := NewCUE(testValues, `
+replicas: int & >0 & <10
+name:     string
+features: metrics: bool
+port:     int | *8080
+`)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "arithmetic", value: `"replicas * 2"`, expected: `4`},
+		{name: "interpolation", value: `"\"\\(name)-svc\""`, expected: `"podinfo-svc"`},
+		{name: "schema default", value: `"port"`, expected: `8080`},
+		{name: "builtin", value: `"strings.ToUpper(name)"`, expected: `"PODINFO"`},
+		{name: "nested", value: `{"replicas": "replicas", "literal": 1}`, expected: `{"replicas": 2, "literal": 1}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := c.Evaluate(json.RawMessage(tc.value))
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+
+	_, err = c.Evaluate(json.RawMessage(`"missing"`))
+	assert.ErrorContains(t, err, `reference "missing" not found`)
+}
+
+func TestCUEValidation(t *testing.T) {
+	_, err := NewCUE(map[string]any{"replicas": 20, "image": map[string]any{"tag": "1.0"}}, `
+replicas: int & <10
+image: tag: =~"^v"
+`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "replicas: invalid value 20 (out of bound <10)")
+	assert.Contains(t, err.Error(), `image.tag: invalid value "1.0" (out of bound =~"^v")`)
+
+	_, err = NewCUE(testValues, `replicas: int &`)
+	assert.ErrorContains(t, err, "invalid schema")
+}
+
+func TestGoTemplate(t *testing.T) {
+	g := NewGoTemplate(testValues)
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "number as string", value: `"{{ .replicas }}"`, expected: `"2"`},
+		{name: "number", value: `"{{ .replicas | toJson }}"`, expected: `2`},
+		{name: "string", value: `"{{ .name }}-svc"`, expected: `"podinfo-svc"`},
+		{name: "quoted", value: `"{{ quote .replicas }}"`, expected: `"\"2\""`},
+		{name: "no actions", value: `"1.0"`, expected: `"1.0"`},
+		{name: "version", value: `"{{ \"1.10\" }}"`, expected: `"1.10"`},
+		{name: "octal", value: `"{{ \"0755\" }}"`, expected: `"0755"`},
+		{name: "yes", value: `"{{ \"yes\" }}"`, expected: `"yes"`},
+		{name: "colon", value: `"{{ \"a: b\" }}"`, expected: `"a: b"`},
+		{name: "default", value: `"{{ index .features \"tracing\" | default false | toJson }}"`, expected: `false`},
+		{name: "functions", value: `"{{ join \",\" .hosts | upper }}"`, expected: `"A.EXAMPLE.COM,B.EXAMPLE.COM"`},
+		{name: "structured", value: `"{{ toJson .features }}"`, expected: `{"metrics": true}`},
+		{name: "json in text", value: `"{{ toJson .features }} "`, expected: `"{\"metrics\":true} "`},
+		{name: "nested", value: `{"replicas": "{{ .replicas | toJson }}", "literal": 1}`, expected: `{"replicas": 2, "literal": 1}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := g.Evaluate(json.RawMessage(tc.value))
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+
+	_, err := g.Evaluate(json.RawMessage(`"{{ .missing }}"`))
+	assert.ErrorContains(t, err, `map has no entry for key "missing"`)
+
+	_, err = g.Evaluate(json.RawMessage(`"{{ .features.tracing | default false }}"`))
+	assert.ErrorContains(t, err, `map has no entry for key "tracing"`)
+
+	_, err = g.Evaluate(json.RawMessage(`"{{ required \"name is required\" (index .features \"name\") }}"`))
+	assert.ErrorContains(t, err, "name is required")
+
+	_, err = g.Evaluate(json.RawMessage(`"{{ env \"HOME\" }}"`))
+	assert.ErrorContains(t, err, `function "env" not defined`)
+}
+
+func TestCondition(t *testing.T) {
+	c, err := NewCondition(map[string]any{
+		"values": testValues,
+		"source": map[string]any{"resourceVersion": "1.2.0"},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   bool
+		err        string
+	}{
+		{name: "flag", expression: `values.features.metrics`, expected: true},
+		{name: "set", expression: `values.replicas != _|_`, expected: true},
+		{name: "unset", expression: `values.tracing != _|_`, expected: false},
+		{name: "version", expression: `strings.HasPrefix(source.resourceVersion, "1.")`, expected: true},
+		{name: "comparison", expression: `values.replicas > 2`, expected: false},
+		{name: "undefined", expression: `values.tracing`, err: "undefined field: tracing"},
+		{name: "not a boolean", expression: `values.name`, err: "cannot use value"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := c.Evaluate(tc.expression)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
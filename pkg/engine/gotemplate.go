@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"sigs.k8s.io/yaml"
+)
+
+// GoTemplate evaluates values as Go templates with the values as data, e.g. "{{ .name }}-svc". The output
+// of a template is a string. A template which consists of a single action ending in toJson, e.g.
+// "{{ .replicas | toJson }}", opts in to structured output and its output is parsed as JSON. Strings
+// without actions are used as they are. Missing values fail the evaluation, values which may be unset are
+// read with index and need a default, e.g. "{{ index .features "tracing" | default false }}". Templates
+// have no access to the environment or files.
+type GoTemplate struct {
+	values map[string]any
+}
+
+var _ Evaluator = &GoTemplate{}
+
+// NewGoTemplate returns an evaluator rendering templates with the values.
+func NewGoTemplate(values map[string]any) *GoTemplate {
+	return &GoTemplate{values: values}
+}
+
+// Evaluate implements Evaluator.
+func (g *GoTemplate) Evaluate(value json.RawMessage) (json.RawMessage, error) {
+	return evaluate(value, func(text string) (any, error) {
+		if !strings.Contains(text, "{{") {
+			return text, nil
+		}
+
+		tmpl, err := template.New("value").Option("missingkey=error").Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+
+		out := &bytes.Buffer{}
+		if err := tmpl.Execute(out, g.values); err != nil {
+			return nil, fmt.Errorf("failed to render template: %w", err)
+		}
+
+		if !isJSON(tmpl) {
+			return out.String(), nil
+		}
+
+		var result any
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			return nil, fmt.Errorf("failed to parse output of template: %w", err)
+		}
+
+		return result, nil
+	})
+}
+
+// isJSON reports whether the template consists of a single action whose pipeline ends in toJson.
+func isJSON(tmpl *template.Template) bool {
+	nodes := tmpl.Tree.Root.Nodes
+	if len(nodes) != 1 {
+		return false
+	}
+
+	action, ok := nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Decl) > 0 {
+		return false
+	}
+
+	last := action.Pipe.Cmds[len(action.Pipe.Cmds)-1]
+	ident, ok := last.Args[0].(*parse.IdentifierNode)
+
+	return ok && ident.Ident == "toJson"
+}
+
+// funcs is the function set of templates. It only contains functions without side effects.
+var funcs = template.FuncMap{
+	"default": func(def, value any) any {
+		if isZero(value) {
+			return def
+		}
+
+		return value
+	},
+	"required": func(message string, value any) (any, error) {
+		if isZero(value) {
+			return nil, errors.New(message)
+		}
+
+		return value, nil
+	},
+	"quote": func(value any) string {
+		return fmt.Sprintf("%q", fmt.Sprint(value))
+	},
+	"toJson": func(value any) (string, error) {
+		out, err := json.Marshal(value)
+
+		return string(out), err
+	},
+	"toYaml": func(value any) (string, error) {
+		out, err := yaml.Marshal(value)
+
+		return strings.TrimSuffix(string(out), "\n"), err
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string {
+		return strings.TrimPrefix(s, prefix)
+	},
+	"trimSuffix": func(suffix, s string) string {
+		return strings.TrimSuffix(s, suffix)
+	},
+	"replace": func(old, replacement, s string) string {
+		return strings.ReplaceAll(s, old, replacement)
+	},
+	"contains": func(substr, s string) bool {
+		return strings.Contains(s, substr)
+	},
+	"hasPrefix": func(prefix, s string) bool {
+		return strings.HasPrefix(s, prefix)
+	},
+	"hasSuffix": func(suffix, s string) bool {
+		return strings.HasSuffix(s, suffix)
+	},
+	"join": func(sep string, values []any) string {
+		items := make([]string, 0, len(values))
+		for _, v := range values {
+			items = append(items, fmt.Sprint(v))
+		}
+
+		return strings.Join(items, sep)
+	},
+	"b64enc": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"b64dec": func(s string) (string, error) {
+		out, err := base64.StdEncoding.DecodeString(s)
+
+		return string(out), err
+	},
+}
+
+func isZero(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case int:
+		return v == 0
+	case int64:
+		return v == 0
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+// Package envelope encrypts content in the age format (https://age-encryption.org) for the recipient of an
+// age X25519 identity. Every message has its own file key, which is stored next to the content encrypted
+// for the recipient, so whoever can read the content also needs access to the identity to decrypt it.
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+// KeyField is the entry of a secret holding the age identity. The suffix follows the conventions of the
+// Flux kustomize-controller, the output of age-keygen can be used as it is.
+const KeyField = "identity.agekey"
+
+// header starts every message in the binary age format.
+var header = []byte("age-encryption.org/v1\n")
+
+// ErrKeyMismatch is returned when content was encrypted for a different identity.
+var ErrKeyMismatch = errors.New("content was encrypted with a different key")
+
+// Key is the age identity content is encrypted for.
+type Key struct {
+	identity *age.X25519Identity
+}
+
+// NewKey reads a key from an age key file holding a single X25519 identity. Empty lines and comments are
+// ignored.
+func NewKey(data []byte) (*Key, error) {
+	identities, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age identity: %w", err)
+	}
+
+	if len(identities) != 1 {
+		return nil, fmt.Errorf("key must hold exactly one age identity, found %d", len(identities))
+	}
+
+	identity, ok := identities[0].(*age.X25519Identity)
+	if !ok {
+		return nil, fmt.Errorf("age identity of type %T is not supported", identities[0])
+	}
+
+	return &Key{identity: identity}, nil
+}
+
+// KeyFromSecretData creates the key stored in the data of a secret.
+func KeyFromSecretData(data map[string][]byte) (*Key, error) {
+	value, ok := data[KeyField]
+	if !ok {
+		return nil, fmt.Errorf("secret has no %s entry", KeyField)
+	}
+
+	return NewKey(value)
+}
+
+// ID identifies the key without revealing it. It's the age recipient of the identity.
+func (k *Key) ID() string {
+	return k.identity.Recipient().String()
+}
+
+// IsEncrypted reports whether the content starts like an age message.
+func IsEncrypted(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, header)
+}
+
+// Encrypt encrypts src for the recipient of the key and writes the age message to dst.
+func Encrypt(dst io.Writer, src io.Reader, kek *Key) error {
+	w, err := age.Encrypt(dst, kek.identity.Recipient())
+	if err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("failed to encrypt content: %w", err)
+	}
+
+	// the last chunk is only written on close
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt content: %w", err)
+	}
+
+	return nil
+}
+
+// Decrypt decrypts an age message written by Encrypt from src and writes the plaintext to dst.
+func Decrypt(dst io.Writer, src io.Reader, kek *Key) error {
+	r := bufio.NewReader(src)
+
+	if prefix, _ := r.Peek(len(header)); !IsEncrypted(prefix) {
+		return errors.New("content is not encrypted")
+	}
+
+	plaintext, err := age.Decrypt(r, kek.identity)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return fmt.Errorf("%w, available key %s", ErrKeyMismatch, kek.ID())
+		}
+
+		return fmt.Errorf("failed to decrypt content: %w", err)
+	}
+
+	// age verifies every chunk before it's returned and fails on truncated content
+	if _, err := io.Copy(dst, plaintext); err != nil {
+		return fmt.Errorf("failed to decrypt content: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkSize is the size of the plaintext chunks of age messages.
+const chunkSize = 64 * 1024
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := newTestKey(t)
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 7} {
+		content := make([]byte, size)
+		_, err := rand.Read(content)
+		require.NoError(t, err)
+
+		encrypted := &bytes.Buffer{}
+		require.NoError(t, Encrypt(encrypted, bytes.NewReader(content), key))
+		assert.True(t, IsEncrypted(encrypted.Bytes()))
+
+		if size > 1 {
+			assert.NotContains(t, encrypted.String(), string(content))
+		}
+
+		decrypted := &bytes.Buffer{}
+		require.NoError(t, Decrypt(decrypted, bytes.NewReader(encrypted.Bytes()), key), "size %d", size)
+		assert.True(t, bytes.Equal(content, decrypted.Bytes()), "size %d", size)
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	key := newTestKey(t)
+	content := bytes.Repeat([]byte("secret"), chunkSize)
+
+	encrypted := &bytes.Buffer{}
+	require.NoError(t, Encrypt(encrypted, bytes.NewReader(content), key))
+	data := encrypted.Bytes()
+
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 1
+
+	testCases := []struct {
+		name string
+		data []byte
+		key  *Key
+		err  string
+	}{
+		{
+			name: "different key",
+			data: data,
+			key:  newTestKey(t),
+			err:  ErrKeyMismatch.Error(),
+		},
+		{
+			name: "tampered content",
+			data: tampered,
+			key:  key,
+			err:  "failed to decrypt content",
+		},
+		{
+			name: "truncated content",
+			data: data[:len(data)-chunkSize],
+			key:  key,
+			err:  "failed to decrypt content",
+		},
+		{
+			name: "plain content",
+			data: content,
+			key:  key,
+			err:  "content is not encrypted",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Decrypt(&bytes.Buffer{}, bytes.NewReader(tc.data), tc.key)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestKeyFromSecretData(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	key, err := KeyFromSecretData(map[string][]byte{
+		KeyField: []byte("# created: 2024-01-01T00:00:00Z\n# public key: " + identity.Recipient().String() + "\n" + identity.String() + "\n"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, identity.Recipient().String(), key.ID())
+
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		data map[string][]byte
+		err  string
+	}{
+		{
+			name: "invalid identity",
+			data: map[string][]byte{KeyField: []byte("not an identity")},
+			err:  "failed to read age identity",
+		},
+		{
+			name: "several identities",
+			data: map[string][]byte{KeyField: []byte(identity.String() + "\n" + other.String() + "\n")},
+			err:  "key must hold exactly one age identity, found 2",
+		},
+		{
+			name: "missing entry",
+			data: map[string][]byte{"key": []byte(identity.String())},
+			err:  "secret has no identity.agekey entry",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := KeyFromSecretData(tc.data)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func newTestKey(t *testing.T) *Key {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	key, err := NewKey([]byte(identity.String()))
+	require.NoError(t, err)
+
+	return key
+}
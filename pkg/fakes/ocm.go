@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/logging"
 	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/datacontext"
@@ -388,22 +389,43 @@ func (c *Component) GetResources() []ocm.ResourceAccess {
 	return accesses
 }
 
+// GetResource looks up a resource by its identity. Like in OCM, the version is only part of the identity
+// of a resource if its name and extra identity aren't unique.
 func (c *Component) GetResource(meta ocmmetav1.Identity) (ocm.ResourceAccess, error) {
+	extra := meta.Copy()
+	delete(extra, ocmmetav1.SystemIdentityName)
+	delete(extra, ocmmetav1.SystemIdentityVersion)
+
+	version, versioned := meta[ocmmetav1.SystemIdentityVersion]
+
 	for _, r := range c.Resources {
-		if r.Name == meta["name"] {
-			if r.ExtraIdentity != nil {
-				if r.ExtraIdentity.Equals(meta.ExtraIdentity()) {
-					return r, nil
-				}
+		if r.Name != meta[ocmmetav1.SystemIdentityName] {
+			continue
+		}
+
+		if r.ExtraIdentity != nil && !r.ExtraIdentity.Equals(extra) {
+			continue
+		}
+
+		if c.isAmbiguous(r) != versioned || (versioned && r.Version != version) {
+			continue
+		}
+
+		return r, nil
+	}
 
-				continue
-			}
+	return nil, errors.ErrNotFound("resource", meta.String())
+}
 
-			return r, nil
+// isAmbiguous returns true if another resource has the same name and extra identity.
+func (c *Component) isAmbiguous(resource *Resource[*compdesc.ResourceMeta]) bool {
+	for _, r := range c.Resources {
+		if r != resource && r.Name == resource.Name && r.ExtraIdentity.Equals(resource.ExtraIdentity) {
+			return true
 		}
 	}
 
-	return nil, fmt.Errorf("failed to find resource on component with identity: %v", meta)
+	return false
 }
 
 func (c *Component) GetName() string {
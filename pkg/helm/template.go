@@ -0,0 +1,170 @@
+// Package helm renders helm charts into plain manifests without access to a cluster, like helm template.
+package helm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Options configure the rendering of a chart.
+type Options struct {
+	// ReleaseName is the name of the release. Defaults to the name of the chart.
+	ReleaseName string
+	// Namespace is the namespace of the release.
+	Namespace string
+	// Values are merged with the default values of the chart.
+	Values map[string]any
+	// IncludeCRDs adds the CRDs of the chart to the manifests.
+	IncludeCRDs bool
+	// DisableHooks omits hooks from the manifests.
+	DisableHooks bool
+	// KubeVersion is the Kubernetes version used for capability checks.
+	KubeVersion string
+	// APIVersions are additional API versions used for capability checks.
+	APIVersions []string
+}
+
+// FindChart returns the directory of the chart below root. That's root itself or its only subdirectory,
+// which is how chart archives are laid out.
+func FindChart(root string) (string, error) {
+	if isChart(root) {
+		return root, nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to read chart directory: %w", err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && isChart(filepath.Join(root, entry.Name())) {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+
+	switch len(dirs) {
+	case 0:
+		return "", errors.New("no chart found")
+	case 1:
+		return filepath.Join(root, dirs[0]), nil
+	}
+
+	return "", fmt.Errorf("found multiple charts: %v", dirs)
+}
+
+func isChart(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, chartutil.ChartfileName))
+
+	return err == nil
+}
+
+// Template renders the chart in dir and returns its manifests. Dependencies must be vendored in the chart,
+// they are never downloaded.
+func Template(ctx context.Context, dir string, opts Options) ([]byte, error) {
+	chart, err := loader.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart: %w", err)
+	}
+
+	if dependencies := chart.Metadata.Dependencies; dependencies != nil {
+		if err := action.CheckDependencies(chart, dependencies); err != nil {
+			return nil, fmt.Errorf("chart dependencies are missing: %w", err)
+		}
+	}
+
+	install := action.NewInstall(&action.Configuration{Log: func(string, ...any) {}})
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+	install.ReleaseName = opts.ReleaseName
+	install.Namespace = opts.Namespace
+	install.IncludeCRDs = opts.IncludeCRDs
+	install.DisableHooks = opts.DisableHooks
+	install.APIVersions = chartutil.VersionSet(opts.APIVersions)
+
+	if install.ReleaseName == "" {
+		install.ReleaseName = chart.Name()
+	}
+
+	if opts.KubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(opts.KubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kube version %s: %w", opts.KubeVersion, err)
+		}
+
+		install.KubeVersion = kubeVersion
+	}
+
+	values := opts.Values
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	rel, err := install.RunWithContext(ctx, chart, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	manifests := bytes.NewBufferString(rel.Manifest)
+	if !opts.DisableHooks {
+		for _, hook := range rel.Hooks {
+			if isTestHook(hook) {
+				continue
+			}
+
+			fmt.Fprintf(manifests, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+		}
+	}
+
+	return manifests.Bytes(), nil
+}
+
+func isTestHook(hook *release.Hook) bool {
+	for _, event := range hook.Events {
+		if event == release.HookTest {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ChartVersion returns the version of the chart below root.
+func ChartVersion(root string) (string, error) {
+	dir, err := FindChart(root)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, err := chartutil.LoadChartfile(filepath.Join(dir, chartutil.ChartfileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to read chart file: %w", err)
+	}
+
+	return metadata.Version, nil
+}
+
+// ValuesFile returns the path of the values file of the chart below root, relative to root.
+func ValuesFile(root string) (string, error) {
+	dir, err := FindChart(root)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(filepath.Join(rel, chartutil.ValuesfileName)), nil
+}
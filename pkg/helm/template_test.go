@@ -0,0 +1,114 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeChart(t *testing.T, root string) {
+	t.Helper()
+
+	files := map[string]string{
+		"podinfo/Chart.yaml": `apiVersion: v2
+name: podinfo
+version: 6.1.0
+`,
+		"podinfo/values.yaml": `replicas: 1
+image: ghcr.io/stefanprodan/podinfo:6.1.0
+`,
+		"podinfo/templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+      - name: podinfo
+        image: {{ .Values.image }}
+`,
+		"podinfo/templates/hook.yaml": `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install
+`,
+		"podinfo/templates/test.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: test-connection
+  annotations:
+    helm.sh/hook: test
+`,
+		"podinfo/crds/crd.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: canaries.podinfo.io
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+func TestFindChart(t *testing.T) {
+	root := t.TempDir()
+	writeChart(t, root)
+
+	dir, err := FindChart(root)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "podinfo"), dir)
+
+	dir, err = FindChart(filepath.Join(root, "podinfo"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "podinfo"), dir)
+
+	_, err = FindChart(t.TempDir())
+	assert.ErrorContains(t, err, "no chart found")
+
+	file, err := ValuesFile(root)
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo/values.yaml", file)
+
+	version, err := ChartVersion(root)
+	require.NoError(t, err)
+	assert.Equal(t, "6.1.0", version)
+}
+
+func TestTemplate(t *testing.T) {
+	root := t.TempDir()
+	writeChart(t, root)
+
+	manifests, err := Template(context.Background(), filepath.Join(root, "podinfo"), Options{
+		ReleaseName: "frontend",
+		Namespace:   "production",
+		Values:      map[string]any{"replicas": 3},
+		IncludeCRDs: true,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(manifests), "name: frontend")
+	assert.Contains(t, string(manifests), "namespace: production")
+	assert.Contains(t, string(manifests), "replicas: 3")
+	assert.Contains(t, string(manifests), "image: ghcr.io/stefanprodan/podinfo:6.1.0")
+	assert.Contains(t, string(manifests), "name: canaries.podinfo.io")
+	assert.Contains(t, string(manifests), "name: migrate")
+	assert.NotContains(t, string(manifests), "name: test-connection")
+
+	manifests, err = Template(context.Background(), filepath.Join(root, "podinfo"), Options{DisableHooks: true})
+	require.NoError(t, err)
+	assert.Contains(t, string(manifests), "name: podinfo")
+	assert.NotContains(t, string(manifests), "name: migrate")
+	assert.NotContains(t, string(manifests), "name: canaries.podinfo.io")
+}
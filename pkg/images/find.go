@@ -0,0 +1,213 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+	"github.com/open-component-model/ocm-controller/pkg/substitute"
+)
+
+// Reference is an image referenced by a document of a manifest.
+type Reference struct {
+	// File is the file of the document relative to the root.
+	File string
+	// Kind and Name identify the document in the file.
+	Kind string
+	Name string
+	// Namespace is the namespace of the document, it's empty if the document doesn't set it.
+	Namespace string
+	// Path is the path of the image in the document, e.g. spec.template.spec.containers[0].image.
+	Path string
+	// Image is the referenced image, e.g. ghcr.io/org/podinfo:6.1.0.
+	Image string
+}
+
+// Target returns the target of the document referencing the image.
+func (r Reference) Target() substitute.Target {
+	return substitute.DocumentTarget(r.File, r.Kind, r.Name)
+}
+
+// podSpecs are the paths of the pod specs of workloads by their kind.
+var podSpecs = map[string]string{
+	"Pod":                   "spec",
+	"PodTemplate":           "template.spec",
+	"Deployment":            "spec.template.spec",
+	"StatefulSet":           "spec.template.spec",
+	"DaemonSet":             "spec.template.spec",
+	"ReplicaSet":            "spec.template.spec",
+	"ReplicationController": "spec.template.spec",
+	"Job":                   "spec.template.spec",
+	"CronJob":               "spec.jobTemplate.spec.template.spec",
+	// Argo Rollouts
+	"Rollout": "spec.template.spec",
+}
+
+// containerLists are the lists of containers of a pod spec.
+var containerLists = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// wellKnownFields are the image fields of well-known custom resources by their kind.
+var wellKnownFields = map[string][]string{
+	// Prometheus operator
+	"Prometheus":   {"spec.image", "spec.containers[*].image", "spec.initContainers[*].image"},
+	"Alertmanager": {"spec.image", "spec.containers[*].image", "spec.initContainers[*].image"},
+	"ThanosRuler":  {"spec.image", "spec.containers[*].image", "spec.initContainers[*].image"},
+	// Knative Services, Kubernetes Services don't have a template
+	"Service": {"spec.template.spec.containers[*].image"},
+}
+
+// Find returns the images referenced by the YAML and JSON files below root: the images of the containers of
+// pod specs, the image fields of well-known custom resources and the fields given for their kind. Files which
+// can't be parsed, e.g. templates, are skipped.
+func Find(root string, files []string, fields []configdata.ImageField) ([]Reference, error) {
+	var refs []Reference
+
+	for _, file := range files {
+		if format := substitute.DetectFormat(file); format != substitute.FormatYAML && format != substitute.FormatJSON {
+			continue
+		}
+
+		docs, err := readDocuments(root, file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			refs = append(refs, findInDocument(file, doc, fields)...)
+		}
+	}
+
+	return refs, nil
+}
+
+func findInDocument(file string, doc map[string]any, fields []configdata.ImageField) []Reference {
+	kind, _ := doc["kind"].(string)
+	if kind == "" {
+		return nil
+	}
+
+	name, namespace := "", ""
+	if metadata, ok := doc["metadata"].(map[string]any); ok {
+		name, _ = metadata["name"].(string)
+		namespace, _ = metadata["namespace"].(string)
+	}
+
+	var paths []string
+	if spec, ok := podSpecs[kind]; ok {
+		for _, list := range containerLists {
+			paths = append(paths, spec+"."+list+"[*].image")
+		}
+	}
+
+	paths = append(paths, wellKnownFields[kind]...)
+
+	for _, field := range fields {
+		if field.Kind == kind {
+			paths = append(paths, field.Path)
+		}
+	}
+
+	var refs []Reference
+
+	// the same image may be selected by a well-known and a configured field
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		lookup(doc, path, "", func(path, image string) {
+			if seen[path] {
+				return
+			}
+
+			seen[path] = true
+			refs = append(refs, Reference{File: file, Kind: kind, Name: name, Namespace: namespace, Path: path, Image: image})
+		})
+	}
+
+	return refs
+}
+
+// lookup calls visit with every string at the path below the value. [*] selects every element of a list,
+// the path passed to visit contains the index of the element instead.
+func lookup(value any, path, prefix string, visit func(path, value string)) {
+	if path == "" {
+		if s, ok := value.(string); ok && s != "" {
+			visit(prefix, s)
+		}
+
+		return
+	}
+
+	head, rest, _ := strings.Cut(path, ".")
+	key, index, isList := strings.Cut(head, "[")
+
+	m, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+
+	child, ok := m[key]
+	if !ok {
+		return
+	}
+
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+
+	if !isList {
+		lookup(child, rest, key, visit)
+
+		return
+	}
+
+	list, ok := child.([]any)
+	if !ok {
+		return
+	}
+
+	index = strings.TrimSuffix(index, "]")
+	for i, item := range list {
+		if index == "*" || index == strconv.Itoa(i) {
+			lookup(item, rest, fmt.Sprintf("%s[%d]", key, i), visit)
+		}
+	}
+}
+
+// readDocuments returns the documents of the file which are maps. It returns no documents if the file
+// isn't valid YAML.
+func readDocuments(root, file string) ([]map[string]any, error) {
+	path, err := securejoin.SecureJoin(root, file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []map[string]any
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc any
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+
+			return nil, nil //nolint:nilerr // files which aren't YAML, e.g. templates, have no images to find
+		}
+
+		if m, ok := doc.(map[string]any); ok {
+			docs = append(docs, m)
+		}
+	}
+}
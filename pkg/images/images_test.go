@@ -0,0 +1,216 @@
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+
+	files := map[string]string{
+		"deploy.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.36
+      containers:
+      - name: podinfo
+        image: ghcr.io/stefanprodan/podinfo:6.1.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+  namespace: jobs
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: ghcr.io/org/cleanup:1.0.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: podinfo
+spec:
+  ports:
+  - port: 80
+`,
+		"monitoring.json": `{"apiVersion": "monitoring.coreos.com/v1", "kind": "Prometheus", "metadata": {"name": "main"},
+  "spec": {"image": "quay.io/prometheus/prometheus:v2.50.0"}}`,
+		"app.yaml": `kind: App
+metadata:
+  name: frontend
+spec:
+  sidecars:
+  - image: envoyproxy/envoy:v1.29.0
+  - name: empty
+`,
+		"templates/deploy.yaml": "image: {{ .Values.image }\n",
+		"README.md":             "# podinfo\n",
+	}
+
+	for file, content := range files {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	refs, err := Find(root, []string{"README.md", "app.yaml", "deploy.yaml", "monitoring.json", "templates/deploy.yaml"},
+		[]configdata.ImageField{{Kind: "App", Path: "spec.sidecars[*].image"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Reference{
+		{File: "app.yaml", Kind: "App", Name: "frontend", Path: "spec.sidecars[0].image", Image: "envoyproxy/envoy:v1.29.0"},
+		{
+			File: "deploy.yaml", Kind: "Deployment", Name: "podinfo",
+			Path: "spec.template.spec.containers[0].image", Image: "ghcr.io/stefanprodan/podinfo:6.1.0",
+		},
+		{File: "deploy.yaml", Kind: "Deployment", Name: "podinfo", Path: "spec.template.spec.initContainers[0].image", Image: "busybox:1.36"},
+		{
+			File: "deploy.yaml", Kind: "CronJob", Name: "cleanup", Namespace: "jobs",
+			Path: "spec.jobTemplate.spec.template.spec.containers[0].image", Image: "ghcr.io/org/cleanup:1.0.0",
+		},
+		{File: "monitoring.json", Kind: "Prometheus", Name: "main", Path: "spec.image", Image: "quay.io/prometheus/prometheus:v2.50.0"},
+	}, refs)
+}
+
+func TestMatch(t *testing.T) {
+	resources := []Resource{
+		{Name: "podinfo", Repository: "mirror/stefanprodan/podinfo"},
+		{Name: "nginx", Label: "docker.io/library/nginx", Repository: "mirror/web"},
+		{Name: "backend", Repository: "mirror/a/backend"},
+		{Name: "backend-legacy", Repository: "mirror/b/backend"},
+		{Name: "redis", Label: "bitnami/redis", Repository: "mirror/redis"},
+	}
+
+	testCases := []struct {
+		image    string
+		expected int
+		err      string
+	}{
+		{image: "ghcr.io/stefanprodan/podinfo:6.1.0", expected: 0},
+		{image: "nginx:1.25", expected: 1},
+		{image: "index.docker.io/library/nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000", expected: 1},
+		{image: "ghcr.io/org/backend:1.0.0", err: "image resources backend, backend-legacy match repository ghcr.io/org/backend"},
+		{image: "ghcr.io/org/redis:7", err: "no image resource matches repository ghcr.io/org/redis"},
+		{image: "redis:7", err: "no image resource matches repository index.docker.io/library/redis"},
+		{image: "{{ .Values.image }}", err: "invalid image reference"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			i, err := Match(tc.image, resources)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, i)
+		})
+	}
+}
+
+func TestInjectPullSecret(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "deploy.yaml"), []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: existing
+      containers:
+      - name: podinfo
+        # localized
+        image: registry.local/podinfo:6.1.0
+      - name: sidecar
+        image: registry.local/envoy:v1.29.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: registry.local/cleanup:1.0.0
+---
+kind: App
+metadata:
+  name: frontend
+spec:
+  image: registry.local/frontend:1.0.0
+`), 0o600))
+
+	refs, err := Find(root, []string{"deploy.yaml"}, []configdata.ImageField{{Kind: "App", Path: "spec.image"}})
+	require.NoError(t, err)
+	require.Len(t, refs, 4)
+
+	for range 2 {
+		require.NoError(t, InjectPullSecret(root, refs, "podinfo-pull-secret"))
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, "deploy.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+        - name: existing
+        - name: podinfo-pull-secret
+      containers:
+        - name: podinfo
+          # localized
+          image: registry.local/podinfo:6.1.0
+        - name: sidecar
+          image: registry.local/envoy:v1.29.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: cleanup
+              image: registry.local/cleanup:1.0.0
+          imagePullSecrets:
+            - name: podinfo-pull-secret
+---
+kind: App
+metadata:
+  name: frontend
+spec:
+  image: registry.local/frontend:1.0.0
+`, string(content))
+}
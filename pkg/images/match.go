@@ -0,0 +1,67 @@
+package images
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Resource is an image resource of a component version an image can be matched to.
+type Resource struct {
+	// Name is the name of the resource.
+	Name string
+	// Label is the repository of the image the manifests refer to the resource with, e.g. ghcr.io/org/podinfo.
+	Label string
+	// Repository is the repository of the location of the resource, e.g. org/podinfo.
+	Repository string
+}
+
+// Match returns the index of the resource the image refers to. Resources with a label match images of the
+// repository in the label, the registry defaults to Docker Hub for both. Resources without a label match
+// images whose repository has the same name, e.g. ghcr.io/org/podinfo:6.1.0 matches a resource located in
+// registry.local/mirror/podinfo. Matches by label are preferred. It fails unless exactly one resource matches.
+func Match(image string, resources []Resource) (int, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return -1, fmt.Errorf("invalid image reference: %w", err)
+	}
+
+	repository := ref.Context()
+
+	var byLabel, byName []int
+
+	for i, resource := range resources {
+		if resource.Label == "" {
+			if resource.Repository != "" && path.Base(resource.Repository) == path.Base(repository.RepositoryStr()) {
+				byName = append(byName, i)
+			}
+
+			continue
+		}
+
+		if label, err := name.NewRepository(resource.Label); err == nil && label.Name() == repository.Name() {
+			byLabel = append(byLabel, i)
+		}
+	}
+
+	matches := byLabel
+	if len(matches) == 0 {
+		matches = byName
+	}
+
+	switch len(matches) {
+	case 0:
+		return -1, fmt.Errorf("no image resource matches repository %s", repository.Name())
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, i := range matches {
+			names = append(names, resources[i].Name)
+		}
+
+		return -1, fmt.Errorf("image resources %s match repository %s", strings.Join(names, ", "), repository.Name())
+	}
+}
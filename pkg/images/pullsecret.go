@@ -0,0 +1,51 @@
+package images
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/open-component-model/ocm-controller/pkg/substitute"
+)
+
+// pullSecretFields are the paths of the objects holding the imagePullSecrets of well-known custom resources.
+var pullSecretFields = map[string]string{
+	"Prometheus":   "spec",
+	"Alertmanager": "spec",
+	"ThanosRuler":  "spec",
+	"Service":      "spec.template.spec",
+}
+
+// InjectPullSecret adds the secret to the imagePullSecrets of the documents of the references, unless they
+// already contain it. Documents of kinds which have no imagePullSecrets are skipped.
+func InjectPullSecret(root string, refs []Reference, secret string) error {
+	seen := make(map[Reference]bool)
+
+	for _, ref := range refs {
+		doc := Reference{File: ref.File, Kind: ref.Kind, Name: ref.Name}
+		if seen[doc] {
+			continue
+		}
+
+		seen[doc] = true
+
+		path, ok := podSpecs[ref.Kind]
+		if !ok {
+			if path, ok = pullSecretFields[ref.Kind]; !ok {
+				continue
+			}
+		}
+
+		field := "." + path + ".imagePullSecrets"
+
+		_, err := substitute.ApplyPatch(root, substitute.Patch{
+			Name:       "imagePullSecrets",
+			Target:     ref.Target(),
+			Expression: fmt.Sprintf(`%[1]s = ((%[1]s // []) + [{"name": %[2]s}] | unique_by(.name))`, field, strconv.Quote(secret)),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add image pull secret to %s %s: %w", ref.Kind, ref.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -200,14 +200,20 @@ func NewRepository(repositoryName string, opts ...Option) (*Repository, error) {
 }
 
 // PushData takes a blob of data and caches it using OCI as a background.
-func (c *Client) PushData(ctx context.Context, data io.ReadCloser, mediaType, name, tag string) (string, int64, error) {
+// The annotations are added to the pushed manifest.
+func (c *Client) PushData(
+	ctx context.Context,
+	data io.ReadCloser,
+	mediaType, name, tag string,
+	annotations map[string]string,
+) (string, int64, error) {
 	repositoryName := fmt.Sprintf("%s/%s", c.OCIRepositoryAddr, name)
 	repo, err := NewRepository(repositoryName, c.WithTransport(ctx))
 	if err != nil {
 		return "", -1, fmt.Errorf("failed create new repository: %w", err)
 	}
 
-	manifest, err := repo.PushStreamingImage(tag, data, mediaType, nil)
+	manifest, err := repo.PushStreamingImage(tag, data, mediaType, annotations)
 	if err != nil {
 		return "", -1, fmt.Errorf("failed to push image: %w", err)
 	}
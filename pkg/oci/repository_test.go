@@ -208,7 +208,7 @@ func TestClient_FetchPush(t *testing.T) {
 			name, err := ocm.HashIdentity(identity)
 			g.Expect(err).NotTo(HaveOccurred())
 			if tc.push {
-				_, _, err := c.PushData(context.Background(), io.NopCloser(bytes.NewBuffer(tc.blob)), "", name, tc.resource.Version)
+				_, _, err := c.PushData(context.Background(), io.NopCloser(bytes.NewBuffer(tc.blob)), "", name, tc.resource.Version, nil)
 				g.Expect(err).NotTo(HaveOccurred())
 				blob, _, _, err := c.FetchDataByIdentity(context.Background(), name, tc.resource.Version)
 				g.Expect(err).NotTo(HaveOccurred())
@@ -296,7 +296,7 @@ func TestClient_DeleteData(t *testing.T) {
 			}
 			name, err := ocm.HashIdentity(identity)
 			g.Expect(err).NotTo(HaveOccurred())
-			_, _, err = c.PushData(context.Background(), io.NopCloser(bytes.NewBuffer(tc.blob)), "", name, tc.resource.Version)
+			_, _, err = c.PushData(context.Background(), io.NopCloser(bytes.NewBuffer(tc.blob)), "", name, tc.resource.Version, nil)
 			g.Expect(err).NotTo(HaveOccurred())
 			g.Expect(c.DeleteData(context.Background(), name, tc.resource.Version)).To(Succeed())
 			exists, err := c.IsCached(context.Background(), name, tc.resource.Version)
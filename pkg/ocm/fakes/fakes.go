@@ -42,6 +42,9 @@ type MockFetcher struct {
 	listComponentVersionsCalledWith     [][]any
 	transferComponentErr                error
 	transferComponentCalledWith         [][]any
+	getImageDigestDigest                string
+	getImageDigestErr                   error
+	getImageDigestCalledWith            [][]any
 }
 
 var _ ocmctrl.Contract = &MockFetcher{}
@@ -176,3 +179,21 @@ func (m *MockFetcher) TransferComponentReturns(err error) {
 func (m *MockFetcher) TransferComponentCallingArgumentsOnCall(i int) []any {
 	return m.transferComponentCalledWith[i]
 }
+
+func (m *MockFetcher) GetImageDigest(_ context.Context, _ ocm.Context, image string) (string, error) {
+	m.getImageDigestCalledWith = append(m.getImageDigestCalledWith, []any{image})
+	return m.getImageDigestDigest, m.getImageDigestErr
+}
+
+func (m *MockFetcher) GetImageDigestReturns(digest string, err error) {
+	m.getImageDigestDigest = digest
+	m.getImageDigestErr = err
+}
+
+func (m *MockFetcher) GetImageDigestCallingArgumentsOnCall(i int) []any {
+	return m.getImageDigestCalledWith[i]
+}
+
+func (m *MockFetcher) GetImageDigestWasNotCalled() bool {
+	return len(m.getImageDigestCalledWith) == 0
+}
@@ -13,12 +13,16 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/containers/image/v5/pkg/compression"
 	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/mandelsoft/vfs/pkg/memoryfs"
 	"github.com/mandelsoft/vfs/pkg/vfs"
 	"github.com/mitchellh/hashstructure/v2"
 	"helm.sh/helm/v3/pkg/registry"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"ocm.software/ocm/api/credentials"
 	"ocm.software/ocm/api/credentials/extensions/repositories/dockerconfig"
 	"ocm.software/ocm/api/ocm"
 	ocmmetav1 "ocm.software/ocm/api/ocm/compdesc/meta/v1"
@@ -30,6 +34,7 @@ import (
 	"ocm.software/ocm/api/ocm/tools/signing"
 	"ocm.software/ocm/api/ocm/tools/transfer"
 	"ocm.software/ocm/api/ocm/tools/transfer/transferhandler/standard"
+	"ocm.software/ocm/api/tech/oci/identity"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -54,6 +59,7 @@ type Contract interface {
 		octx ocm.Context,
 		repositoryURL, name, version string,
 	) (ocm.ComponentVersionAccess, error)
+	GetImageDigest(ctx context.Context, octx ocm.Context, image string) (string, error)
 	GetLatestValidComponentVersion(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentVersion) (string, error)
 	ListComponentVersions(ctx context.Context, logger logr.Logger, octx ocm.Context, obj *v1alpha1.ComponentVersion) ([]Version, error)
 	VerifyComponent(ctx context.Context, octx ocm.Context, obj *v1alpha1.ComponentVersion, version string) (bool, error)
@@ -270,8 +276,16 @@ func (c *Client) GetResource(
 		return nil, "", -1, fmt.Errorf("failed to autodecompress content: %w", err)
 	}
 
-	// We need to push the media type... And construct the right layers I guess.
-	digest, size, err := c.cache.PushData(ctx, decompressedReader, mediaType, name, version)
+	// Only helm charts are pushed with their original layer media type, because the content is
+	// compressed by the cache. The original media type is recorded in the annotations instead.
+	layerMediaType := ""
+	if mediaType == registry.ChartLayerMediaType {
+		layerMediaType = mediaType
+	}
+
+	annotations := resourceArtifactMetadata(res, cva, mediaType).Annotations()
+
+	digest, size, err := c.cache.PushData(ctx, decompressedReader, layerMediaType, name, version, annotations)
 	if err != nil {
 		return nil, "", -1, fmt.Errorf("failed to cache blob: %w", err)
 	}
@@ -285,6 +299,36 @@ func (c *Client) GetResource(
 	return dataReader, digest, size, nil
 }
 
+// GetImageDigest returns the manifest digest of an image by sending a HEAD request to its registry. Credentials
+// configured in the OCM context for the registry are used.
+func (c *Client) GetImageDigest(ctx context.Context, octx ocm.Context, image string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference: %w", err)
+	}
+
+	opts := []remote.Option{remote.WithContext(ctx)}
+
+	creds, err := identity.GetCredentials(octx, ref.Context().RegistryStr(), ref.Context().RepositoryStr())
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials for %s: %w", ref.Context().RegistryStr(), err)
+	}
+
+	if creds != nil {
+		opts = append(opts, remote.WithAuth(&authn.Basic{
+			Username: creds.GetProperty(credentials.ATTR_USERNAME),
+			Password: creds.GetProperty(credentials.ATTR_PASSWORD),
+		}))
+	}
+
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch digest of %s: %w", image, err)
+	}
+
+	return desc.Digest.String(), nil
+}
+
 // GetComponentVersion returns a component Version. It's the caller's responsibility to clean it up and close the component Version once done with it.
 func (c *Client) GetComponentVersion(
 	_ context.Context,
@@ -565,7 +609,7 @@ func (c *Client) TransferComponent(
 // Because of that, we need to create our own downloader when we are dealing with
 // helm charts.
 func (c *Client) fetchResourceReader(res ocm.ResourceAccess, cva ocm.ComponentVersionAccess) (_ io.ReadCloser, _ string, err error) {
-	if res.Meta().Type == "helmChart" {
+	if res.Meta().Type == v1alpha1.HelmChartResourceType {
 		return c.fetchHelmChartResource(res, cva, err)
 	}
 
@@ -580,8 +624,26 @@ func (c *Client) fetchResourceReader(res ocm.ResourceAccess, cva ocm.ComponentVe
 		return nil, "", fmt.Errorf("failed to fetch reader: %w", err)
 	}
 
-	// Ignore the media type as we set it to a default in OCI package
-	return reader, "", nil
+	return reader, access.MimeType(), nil
+}
+
+// resourceArtifactMetadata describes the origin of a resource so it can be stored with the cached data.
+func resourceArtifactMetadata(res ocm.ResourceAccess, cva ocm.ComponentVersionAccess, mediaType string) *v1alpha1.ArtifactMetadata {
+	meta := res.Meta()
+
+	metadata := &v1alpha1.ArtifactMetadata{
+		MediaType:        mediaType,
+		ResourceType:     meta.Type,
+		ResourceIdentity: meta.GetIdentity(cva.GetDescriptor().Resources),
+		ComponentName:    cva.GetName(),
+		ComponentVersion: cva.GetVersion(),
+	}
+
+	if meta.Digest != nil {
+		metadata.SourceDigest = meta.Digest.Value
+	}
+
+	return metadata
 }
 
 func (c *Client) fetchHelmChartResource(res ocm.ResourceAccess, cva ocm.ComponentVersionAccess, err error) (io.ReadCloser, string, error) {
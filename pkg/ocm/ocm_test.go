@@ -119,6 +119,12 @@ func TestClient_GetResource(t *testing.T) {
 
 	assert.Equal(t, "sha-14469167939644886767", args.Name, "pushed name did not match constructed name from identity of the resource")
 	assert.Equal(t, resourceRef.Version, args.Version)
+	assert.Empty(t, args.MediaType, "only helm charts are pushed with their own layer media type")
+	assert.Equal(t, "ociBlob", args.Annotations[v1alpha1.ArtifactMediaTypeAnnotation])
+	assert.Equal(t, "ociBlob", args.Annotations[v1alpha1.ArtifactResourceTypeAnnotation])
+	assert.Equal(t, component, args.Annotations[v1alpha1.ArtifactComponentNameAnnotation])
+	assert.Equal(t, "v0.0.1", args.Annotations[v1alpha1.ArtifactComponentVersionAnnotation])
+	assert.Contains(t, args.Annotations[v1alpha1.ArtifactResourceIdentityAnnotation], resource)
 }
 
 func TestClient_GetResourceFromNestedComponent(t *testing.T) {
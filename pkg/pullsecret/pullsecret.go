@@ -0,0 +1,93 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Keys of the username and password of credential secrets, which OCM uses as well.
+const (
+	UsernameKey = "username"
+	PasswordKey = "password"
+)
+
+// dockerHub is the registry of Docker Hub images, which docker config files key as https://index.docker.io/v1/.
+const dockerHub = "index.docker.io"
+
+// dockerConfig is the content of a .dockerconfigjson.
+type dockerConfig struct {
+	Auths map[string]json.RawMessage `json:"auths"`
+}
+
+type dockerAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// Generate returns a .dockerconfigjson with the credentials of the secret for the registries. The data of the
+// secret is either a .dockerconfigjson, whose entries for other registries are dropped, or a username and a
+// password, which are used for every registry. It fails if the credentials of a registry are missing.
+func Generate(data map[string][]byte, registries []string) ([]byte, error) {
+	config := dockerConfig{Auths: make(map[string]json.RawMessage, len(registries))}
+
+	switch {
+	case len(data[corev1.DockerConfigJsonKey]) > 0:
+		source := dockerConfig{}
+		if err := json.Unmarshal(data[corev1.DockerConfigJsonKey], &source); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", corev1.DockerConfigJsonKey, err)
+		}
+
+		for key, auth := range source.Auths {
+			if registry := registryOf(key); slices.Contains(registries, registry) {
+				config.Auths[registry] = auth
+			}
+		}
+
+		for _, registry := range registries {
+			if _, ok := config.Auths[registry]; !ok {
+				return nil, fmt.Errorf("%s has no credentials for registry %s", corev1.DockerConfigJsonKey, registry)
+			}
+		}
+	case len(data[UsernameKey]) > 0 && len(data[PasswordKey]) > 0:
+		username, password := string(data[UsernameKey]), string(data[PasswordKey])
+
+		auth, err := json.Marshal(dockerAuth{
+			Username: username,
+			Password: password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, registry := range registries {
+			config.Auths[registry] = auth
+		}
+	default:
+		return nil, errors.New("secret contains neither a .dockerconfigjson nor a username and password")
+	}
+
+	return json.Marshal(config)
+}
+
+// registryOf returns the registry of a key of a docker config, which may be a URL like https://ghcr.io/v2/.
+func registryOf(key string) string {
+	_, registry, ok := strings.Cut(key, "://")
+	if !ok {
+		registry = key
+	}
+
+	registry, _, _ = strings.Cut(registry, "/")
+	if registry == "docker.io" {
+		return dockerHub
+	}
+
+	return registry
+}
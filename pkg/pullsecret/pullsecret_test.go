@@ -0,0 +1,36 @@
+package pullsecret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGenerate(t *testing.T) {
+	dockerConfig := map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {
+  "https://ghcr.io/v2/": {"auth": "Z2hjcjpzZWNyZXQ="},
+  "https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+  "quay.io": {"auth": "cXVheTpzZWNyZXQ="}
+}}`)}
+
+	data, err := Generate(dockerConfig, []string{"ghcr.io", "index.docker.io"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"auths": {
+  "ghcr.io": {"auth": "Z2hjcjpzZWNyZXQ="},
+  "index.docker.io": {"auth": "aHViOnNlY3JldA=="}
+}}`, string(data))
+
+	_, err = Generate(dockerConfig, []string{"registry.local:5000"})
+	assert.ErrorContains(t, err, ".dockerconfigjson has no credentials for registry registry.local:5000")
+
+	data, err = Generate(map[string][]byte{UsernameKey: []byte("ocm"), PasswordKey: []byte("secret")}, []string{"registry.local:5000"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"auths": {
+  "registry.local:5000": {"username": "ocm", "password": "secret", "auth": "b2NtOnNlY3JldA=="}
+}}`, string(data))
+
+	_, err = Generate(map[string][]byte{".ocmcredentialconfig": []byte("{}")}, []string{"ghcr.io"})
+	assert.ErrorContains(t, err, "neither a .dockerconfigjson nor a username and password")
+}
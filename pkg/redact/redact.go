@@ -0,0 +1,149 @@
+package redact
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces sensitive values in messages.
+const Mask = "***"
+
+// Redactor collects sensitive values and removes them from messages and errors before they end up in
+// events, logs or the status of an object.
+type Redactor struct {
+	mu     sync.RWMutex
+	values map[string]struct{}
+}
+
+// New creates an empty Redactor.
+func New() *Redactor {
+	return &Redactor{values: map[string]struct{}{}}
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the redactor.
+func NewContext(ctx context.Context, r *Redactor) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the redactor of the context. If the context has none, a redactor is returned which
+// only redacts values added to it directly.
+func FromContext(ctx context.Context) *Redactor {
+	if r, ok := ctx.Value(contextKey{}).(*Redactor); ok {
+		return r
+	}
+
+	return New()
+}
+
+// Add marks every scalar of the value as sensitive. Maps and lists are walked recursively. Booleans
+// aren't masked, replacing every true and false would make messages unreadable without hiding anything.
+func (r *Redactor) Add(value any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.add(value)
+}
+
+func (r *Redactor) add(value any) {
+	switch v := value.(type) {
+	case nil, bool:
+	case string:
+		r.addString(v)
+	case map[string]any:
+		for _, item := range v {
+			r.add(item)
+		}
+	case []any:
+		for _, item := range v {
+			r.add(item)
+		}
+	default:
+		r.addString(fmt.Sprint(v))
+	}
+}
+
+func (r *Redactor) addString(s string) {
+	if s == "" {
+		return
+	}
+
+	r.values[s] = struct{}{}
+
+	// values embedded in JSON or in quoted error messages are escaped
+	if quoted, err := json.Marshal(s); err == nil {
+		r.values[string(quoted[1:len(quoted)-1])] = struct{}{}
+	}
+}
+
+// Sensitive returns true if at least one sensitive value has been added.
+func (r *Redactor) Sensitive() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.values) > 0
+}
+
+// String replaces all sensitive values in the message with the mask.
+func (r *Redactor) String(msg string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.values) == 0 {
+		return msg
+	}
+
+	values := make([]string, 0, len(r.values))
+	for v := range r.values {
+		values = append(values, v)
+	}
+
+	// replace longer values first, so values containing other values are fully masked
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+
+		return values[i] < values[j]
+	})
+
+	var pairs []string
+	for _, v := range values {
+		pairs = append(pairs, v, Mask)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// Error returns an error with the sensitive values removed from its message. The original error is kept
+// as the wrapped error, so errors.Is and errors.As keep working.
+func (r *Redactor) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := r.String(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+
+	return &redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
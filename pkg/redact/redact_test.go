@@ -0,0 +1,56 @@
+package redact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor(t *testing.T) {
+	r := New()
+	assert.False(t, r.Sensitive())
+
+	r.Add(map[string]any{
+		"password": "s3cr3t",
+		"token":    "s3cr3t-token",
+		"port":     5432,
+		"enabled":  true,
+		"short":    "abc",
+		"pin":      "42",
+		"empty":    "",
+		"cert":     "line1\nline2",
+		"list":     []any{"element"},
+	})
+	assert.True(t, r.Sensitive())
+
+	assert.Equal(t, "password *** token *** port *** short *** pin *** enabled true", r.String("password s3cr3t token s3cr3t-token port 5432 short abc pin 42 enabled true"))
+	assert.Equal(t, `cert: "***" ***`, r.String(`cert: "line1\nline2" element`))
+}
+
+func TestRedactorError(t *testing.T) {
+	sentinel := errors.New("sentinel")
+
+	r := New()
+	r.Add("s3cr3t")
+
+	err := r.Error(fmt.Errorf("failed with value s3cr3t: %w", sentinel))
+	assert.EqualError(t, err, "failed with value ***: sentinel")
+	assert.ErrorIs(t, err, sentinel)
+
+	unchanged := errors.New("nothing to hide")
+	assert.Same(t, unchanged, r.Error(unchanged))
+	assert.NoError(t, r.Error(nil))
+}
+
+func TestContext(t *testing.T) {
+	r := New()
+	ctx := NewContext(context.Background(), r)
+
+	FromContext(ctx).Add("s3cr3t")
+	assert.True(t, r.Sensitive())
+
+	assert.False(t, FromContext(context.Background()).Sensitive())
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/open-component-model/ocm-controller/api/v1alpha1"
+	"github.com/open-component-model/ocm-controller/pkg/envelope"
+)
+
+// ErrEncryption defines an error that occurs when snapshot content can't be encrypted or decrypted.
+var ErrEncryption = errors.New("snapshot encryption failed")
+
+// EncryptionKey returns the key referenced by the encryption settings of a snapshot.
+func EncryptionKey(ctx context.Context, c client.Reader, namespace string, encryption *v1alpha1.SnapshotEncryption) (*envelope.Key, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{
+		Name:      encryption.SecretRef.Name,
+		Namespace: namespace,
+	}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("%w: failed to get encryption secret %s: %w", ErrEncryption, key.Name, err)
+	}
+
+	kek, err := envelope.KeyFromSecretData(secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid encryption secret %s: %w", ErrEncryption, key.Name, err)
+	}
+
+	return kek, nil
+}
+
+// Decrypt reads the encrypted content of the last reconciled digest of a snapshot with the key recorded in
+// its status.
+func Decrypt(ctx context.Context, c client.Reader, snapshot *v1alpha1.Snapshot, content io.Reader) ([]byte, error) {
+	kek, err := EncryptionKey(ctx, c, snapshot.Namespace, snapshot.Status.LastReconciledEncryption)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := &bytes.Buffer{}
+	if err := envelope.Decrypt(plaintext, content, kek); err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt snapshot %s: %w", ErrEncryption, snapshot.Name, err)
+	}
+
+	return plaintext.Bytes(), nil
+}
+
+// encryptFile encrypts a file into a new temporary file and returns its path.
+func encryptFile(path string, kek *envelope.Key) (_ string, err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "snapshot-artifact-*.enc")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if closeErr := dst.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+
+		if err != nil {
+			err = errors.Join(err, os.Remove(dst.Name()))
+		}
+	}()
+
+	if err := envelope.Encrypt(dst, src, kek); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrEncryption, err)
+	}
+
+	return dst.Name(), nil
+}
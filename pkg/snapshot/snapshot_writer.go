@@ -19,9 +19,16 @@ import (
 )
 
 // Writer creates a snapshot using an artifact path as location for the snapshot
-// data.
+// data. The artifact metadata is optional and describes where the data originates from.
 type Writer interface {
-	Write(ctx context.Context, owner v1alpha1.SnapshotWriter, sourceDir string, identity ocmmetav1.Identity) (string, int64, error)
+	Write(
+		ctx context.Context,
+		owner v1alpha1.SnapshotWriter,
+		sourceDir string,
+		identity ocmmetav1.Identity,
+		artifact *v1alpha1.ArtifactMetadata,
+		encryption *v1alpha1.SnapshotEncryption,
+	) (string, int64, error)
 }
 
 // OCIWriter writes snapshot data into the cluster-local OCI cache.
@@ -45,6 +52,8 @@ func (w *OCIWriter) Write(
 	owner v1alpha1.SnapshotWriter,
 	sourceDir string,
 	identity ocmmetav1.Identity,
+	artifact *v1alpha1.ArtifactMetadata,
+	encryption *v1alpha1.SnapshotEncryption,
 ) (_ string, _ int64, err error) {
 	logger := log.FromContext(ctx).WithName("snapshot-writer")
 
@@ -60,7 +69,27 @@ func (w *OCIWriter) Write(
 
 	logger.V(v1alpha1.LevelDebug).Info("built tar file")
 
-	file, err := os.Open(artifactPath.Name())
+	contentPath := artifactPath.Name()
+	mediaType := ""
+	annotations := artifact.Annotations()
+
+	if encryption != nil {
+		kek, err := EncryptionKey(ctx, w.Client, owner.GetNamespace(), encryption)
+		if err != nil {
+			return "", -1, err
+		}
+
+		if contentPath, err = encryptFile(artifactPath.Name(), kek); err != nil {
+			return "", -1, fmt.Errorf("failed to encrypt archive: %w", err)
+		}
+
+		mediaType = v1alpha1.EncryptedSnapshotMediaType
+		annotations[v1alpha1.EncryptionKeyIDAnnotation] = kek.ID()
+
+		logger.V(v1alpha1.LevelDebug).Info("encrypted tar file", "keyID", kek.ID())
+	}
+
+	file, err := os.Open(contentPath)
 	if err != nil {
 		return "", -1, fmt.Errorf("failed to open created archive: %w", err)
 	}
@@ -69,8 +98,10 @@ func (w *OCIWriter) Write(
 		if closeErr := file.Close(); closeErr != nil && !errors.Is(closeErr, os.ErrClosed) {
 			err = errors.Join(err, closeErr)
 		}
-		if removeErr := os.Remove(artifactPath.Name()); removeErr != nil {
-			err = errors.Join(err, removeErr)
+		for _, path := range []string{artifactPath.Name(), contentPath} {
+			if removeErr := os.Remove(path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+				err = errors.Join(err, removeErr)
+			}
 		}
 	}()
 
@@ -91,7 +122,7 @@ func (w *OCIWriter) Write(
 		tag = v
 	}
 
-	snapshotDigest, size, err := w.Cache.PushData(ctx, file, "", name, tag)
+	snapshotDigest, size, err := w.Cache.PushData(ctx, file, mediaType, name, tag, annotations)
 	if err != nil {
 		return "", -1, fmt.Errorf("failed to push blob to local registry: %w", err)
 	}
@@ -112,9 +143,11 @@ func (w *OCIWriter) Write(
 			}
 		}
 		snapshotCR.Spec = v1alpha1.SnapshotSpec{
-			Identity: identity,
-			Digest:   snapshotDigest,
-			Tag:      tag,
+			Identity:   identity,
+			Digest:     snapshotDigest,
+			Tag:        tag,
+			Artifact:   artifact,
+			Encryption: encryption,
 		}
 
 		return nil
@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"archive/tar"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -26,7 +27,40 @@ func buildTar(artifactPath, sourceDir string) error {
 		}
 	}()
 
-	tw := tar.NewWriter(tf)
+	if err := writeTar(tf, sourceDir); err != nil {
+		tf.Close()
+
+		return err
+	}
+	if err := tf.Close(); err != nil {
+		return err
+	}
+
+	const mode = 0o640
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, artifactPath)
+}
+
+// TarDirectory returns an uncompressed tar archive of the directory, as it's stored in a snapshot.
+func TarDirectory(sourceDir string) ([]byte, error) {
+	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("invalid source dir path: %s", sourceDir)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeTar(buf, sourceDir); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeTar writes the regular files and directories of sourceDir without environment specific data.
+func writeTar(w io.Writer, sourceDir string) error {
+	tw := tar.NewWriter(w)
 
 	if err := filepath.Walk(sourceDir, func(p string, fi os.FileInfo, err error) error {
 		if err != nil {
@@ -85,23 +119,9 @@ func buildTar(artifactPath, sourceDir string) error {
 		return f.Close()
 	}); err != nil {
 		tw.Close()
-		tf.Close()
-
-		return err
-	}
-	if err := tw.Close(); err != nil {
-		tf.Close()
 
 		return err
 	}
-	if err := tf.Close(); err != nil {
-		return err
-	}
 
-	const mode = 0o640
-	if err := os.Chmod(tmpName, mode); err != nil {
-		return err
-	}
-
-	return os.Rename(tmpName, artifactPath)
+	return tw.Close()
 }
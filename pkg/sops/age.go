@@ -0,0 +1,31 @@
+package sops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// readAgeIdentities reads the age identities of a key file, one per line. Empty lines and comments are
+// ignored.
+func readAgeIdentities(data []byte) ([]age.Identity, error) {
+	return age.ParseIdentities(bytes.NewReader(data))
+}
+
+// decryptAge decrypts an armored age message with the first identity matching one of its recipients.
+func decryptAge(identities []age.Identity, armored []byte) ([]byte, error) {
+	if len(identities) == 0 {
+		return nil, errors.New("no age identity available")
+	}
+
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(armored)), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age message: %w", err)
+	}
+
+	return io.ReadAll(r)
+}
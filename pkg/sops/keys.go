@@ -0,0 +1,112 @@
+package sops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/getsops/sops/v3/keyservice"
+)
+
+// Suffixes of the secret keys holding age identities and armored PGP private keys. They follow the
+// conventions of the Flux kustomize-controller, so the same secret can be used for both.
+const (
+	AgeKeySuffix = ".agekey"
+	PGPKeySuffix = ".asc"
+)
+
+// ErrNoKey is returned when none of the available keys can decrypt the data key of a document.
+var ErrNoKey = errors.New("no key available to decrypt the document")
+
+// Keys are the private keys used to decrypt the data key of documents.
+type Keys struct {
+	age []age.Identity
+	pgp openpgp.EntityList
+}
+
+// ParseKeys reads the keys from the data of a secret. Entries without a known suffix are ignored.
+func ParseKeys(data map[string][]byte) (*Keys, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	keys := &Keys{}
+
+	for _, name := range names {
+		switch {
+		case strings.HasSuffix(name, AgeKeySuffix):
+			identities, err := readAgeIdentities(data[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read age identities from %s: %w", name, err)
+			}
+
+			keys.age = append(keys.age, identities...)
+		case strings.HasSuffix(name, PGPKeySuffix):
+			entities, err := readPGPKeys(data[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read pgp keys from %s: %w", name, err)
+			}
+
+			keys.pgp = append(keys.pgp, entities...)
+		}
+	}
+
+	return keys, nil
+}
+
+func (k *Keys) empty() bool {
+	return k == nil || (len(k.age) == 0 && len(k.pgp) == 0)
+}
+
+// keyService is a sops key service decrypting data keys with the keys of a secret only. The default key
+// service of sops would use the keys found in the environment of the controller. The errors of failed
+// attempts are kept, sops only reports how many key groups failed.
+type keyService struct {
+	keys *Keys
+	errs []error
+}
+
+var _ keyservice.KeyServiceServer = &keyService{}
+
+// Encrypt isn't supported, documents are only decrypted.
+func (s *keyService) Encrypt(context.Context, *keyservice.EncryptRequest) (*keyservice.EncryptResponse, error) {
+	return nil, errors.New("encryption is not supported")
+}
+
+// Decrypt decrypts a data key encrypted for an age recipient or a PGP key.
+func (s *keyService) Decrypt(_ context.Context, req *keyservice.DecryptRequest) (*keyservice.DecryptResponse, error) {
+	var (
+		plaintext []byte
+		err       error
+	)
+
+	switch key := req.GetKey().GetKeyType().(type) {
+	case *keyservice.Key_AgeKey:
+		plaintext, err = decryptAge(s.keys.age, req.GetCiphertext())
+		if err != nil {
+			err = fmt.Errorf("age recipient %s: %w", key.AgeKey.GetRecipient(), err)
+		}
+	case *keyservice.Key_PgpKey:
+		plaintext, err = decryptPGP(s.keys.pgp, string(req.GetCiphertext()))
+		if err != nil {
+			err = fmt.Errorf("pgp key %s: %w", key.PgpKey.GetFingerprint(), err)
+		}
+	default:
+		err = fmt.Errorf("key type %T is not supported", key)
+	}
+
+	if err != nil {
+		s.errs = append(s.errs, err)
+
+		return nil, err
+	}
+
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
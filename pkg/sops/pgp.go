@@ -0,0 +1,47 @@
+package sops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// readPGPKeys reads armored PGP private keys. Keys protected by a passphrase can't be used.
+func readPGPKeys(data []byte) (openpgp.EntityList, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entities {
+		if e.PrivateKey == nil {
+			return nil, fmt.Errorf("key %X has no private key", e.PrimaryKey.Fingerprint)
+		}
+	}
+
+	return entities, nil
+}
+
+// decryptPGP decrypts an armored PGP message with the keys of the key ring.
+func decryptPGP(keyRing openpgp.EntityList, armored string) ([]byte, error) {
+	if len(keyRing) == 0 {
+		return nil, errors.New("no pgp key available")
+	}
+
+	block, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pgp armor: %w", err)
+	}
+
+	md, err := openpgp.ReadMessage(block.Body, keyRing, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt pgp message: %w", err)
+	}
+
+	return io.ReadAll(md.UnverifiedBody)
+}
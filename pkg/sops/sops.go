@@ -0,0 +1,85 @@
+// Package sops decrypts YAML and JSON documents encrypted with SOPS (https://getsops.io) using age or
+// PGP keys.
+package sops
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
+	sopsyaml "github.com/getsops/sops/v3/stores/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+// indent is the indentation of decrypted documents.
+const indent = 2
+
+// IsEncrypted reports whether the document is encrypted with sops.
+func IsEncrypted(data []byte) bool {
+	var doc struct {
+		Sops *struct {
+			MAC string `yaml:"mac"`
+		} `yaml:"sops"`
+	}
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+
+	return doc.Sops != nil && doc.Sops.MAC != ""
+}
+
+// Decrypt decrypts a YAML or JSON document encrypted with sops and returns it as YAML without the sops
+// metadata. The integrity of the document is verified with its message authentication code.
+func Decrypt(data []byte, keys *Keys) ([]byte, error) {
+	if keys.empty() {
+		return nil, ErrNoKey
+	}
+
+	// JSON is YAML, so the YAML store reads both
+	store := sopsyaml.NewStore(&config.YAMLStoreConfig{Indent: indent})
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load document: %w", err)
+	}
+
+	service := &keyService{keys: keys}
+
+	key, err := tree.Metadata.GetDataKeyWithKeyServices(
+		[]keyservice.KeyServiceClient{keyservice.NewCustomLocalClient(service)}, sops.DefaultDecryptionOrder,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoKey, errors.Join(append([]error{err}, service.errs...)...))
+	}
+
+	cipher := aes.NewCipher()
+
+	mac, err := tree.Decrypt(key, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt document: %w", err)
+	}
+
+	// the message authentication code covers the values which aren't encrypted as well
+	originalMAC, err := cipher.Decrypt(
+		tree.Metadata.MessageAuthenticationCode, key, tree.Metadata.LastModified.Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt message authentication code: %w", err)
+	}
+
+	if originalMAC != mac {
+		return nil, errors.New("message authentication code mismatch")
+	}
+
+	out, err := store.EmitPlainFile(tree.Branches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,248 @@
+package sops
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/config"
+	sopskeys "github.com/getsops/sops/v3/keys"
+	"github.com/getsops/sops/v3/pgp"
+	sopsyaml "github.com/getsops/sops/v3/stores/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const plainValues = `database:
+  host: db.internal
+  password: s3cret
+  port: 5432
+enabled: true
+ratio: 0.5
+regions:
+  - eu-west
+  - us-east
+name_unencrypted: plain
+`
+
+func TestDecryptAge(t *testing.T) {
+	dataKey := randomBytes(t, 32)
+	identity, key := newAgeKey(t, dataKey)
+	encrypted := encryptDocument(t, plainValues, dataKey, key)
+
+	assert.True(t, IsEncrypted([]byte(encrypted)))
+	assert.NotContains(t, encrypted, "s3cret")
+
+	keys, err := ParseKeys(map[string][]byte{
+		"identity.agekey": []byte("# created: 2024-01-01T00:00:00Z\n" + identity.String() + "\n"),
+		"other":           []byte("ignored"),
+	})
+	require.NoError(t, err)
+
+	decrypted, err := Decrypt([]byte(encrypted), keys)
+	require.NoError(t, err)
+	assert.Equal(t, plainValues, string(decrypted))
+}
+
+func TestDecryptPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("ocm", "", "ocm@example.com", nil)
+	require.NoError(t, err)
+
+	private := &bytes.Buffer{}
+	w, err := armor.Encode(private, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+
+	dataKey := randomBytes(t, 32)
+
+	message := &bytes.Buffer{}
+	aw, err := armor.Encode(message, "PGP MESSAGE", nil)
+	require.NoError(t, err)
+	pw, err := openpgp.Encrypt(aw, openpgp.EntityList{entity}, nil, nil, nil)
+	require.NoError(t, err)
+	_, err = pw.Write(dataKey)
+	require.NoError(t, err)
+	require.NoError(t, pw.Close())
+	require.NoError(t, aw.Close())
+
+	key := &pgp.MasterKey{
+		Fingerprint:  fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint),
+		EncryptedKey: message.String(),
+		CreationDate: time.Now().UTC(),
+	}
+	encrypted := encryptDocument(t, plainValues, dataKey, key)
+
+	keys, err := ParseKeys(map[string][]byte{"sops.asc": private.Bytes()})
+	require.NoError(t, err)
+
+	decrypted, err := Decrypt([]byte(encrypted), keys)
+	require.NoError(t, err)
+	assert.Equal(t, plainValues, string(decrypted))
+}
+
+func TestDecryptErrors(t *testing.T) {
+	dataKey := randomBytes(t, 32)
+	identity, key := newAgeKey(t, dataKey)
+	otherIdentity, _ := newAgeKey(t, dataKey)
+	encrypted := encryptDocument(t, plainValues, dataKey, key)
+
+	keys, err := ParseKeys(map[string][]byte{"identity.agekey": []byte(identity.String())})
+	require.NoError(t, err)
+
+	otherKeys, err := ParseKeys(map[string][]byte{"identity.agekey": []byte(otherIdentity.String())})
+	require.NoError(t, err)
+
+	// swap the encrypted values of two keys, which is only detected by the additional data
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(encrypted), &doc))
+	database := doc["database"].(map[string]any)
+	swapped := strings.Replace(encrypted, database["host"].(string), "SWAP", 1)
+	swapped = strings.Replace(swapped, database["password"].(string), database["host"].(string), 1)
+	swapped = strings.Replace(swapped, "SWAP", database["password"].(string), 1)
+
+	testCases := []struct {
+		name string
+		data string
+		keys *Keys
+		err  string
+		is   error
+	}{
+		{
+			name: "no keys",
+			data: encrypted,
+			keys: &Keys{},
+			is:   ErrNoKey,
+		},
+		{
+			name: "wrong key",
+			data: encrypted,
+			keys: otherKeys,
+			is:   ErrNoKey,
+			err:  "no identity matched any of the recipients",
+		},
+		{
+			name: "modified unencrypted value",
+			data: strings.Replace(encrypted, "name_unencrypted: plain", "name_unencrypted: changed", 1),
+			keys: keys,
+			err:  "message authentication code mismatch",
+		},
+		{
+			name: "swapped values",
+			data: swapped,
+			keys: keys,
+			err:  "failed to decrypt document",
+		},
+		{
+			name: "not encrypted",
+			data: plainValues,
+			keys: keys,
+			err:  "sops metadata not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decrypt([]byte(tc.data), tc.keys)
+			require.Error(t, err)
+
+			if tc.is != nil {
+				assert.ErrorIs(t, err, tc.is)
+			}
+
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.False(t, IsEncrypted([]byte(plainValues)))
+	assert.False(t, IsEncrypted([]byte("- a\n- b\n")))
+	assert.False(t, IsEncrypted([]byte("sops: enabled\n")))
+	assert.True(t, IsEncrypted([]byte(`{"a": "ENC[AES256_GCM,data:AA==,iv:AA==,tag:AA==,type:str]", "sops": {"mac": "ENC[...]"}}`)))
+}
+
+func TestParseKeys(t *testing.T) {
+	_, err := ParseKeys(map[string][]byte{"identity.agekey": []byte("# no keys\n")})
+	assert.ErrorContains(t, err, "failed to read age identities from identity.agekey: no secret keys found")
+
+	_, err = ParseKeys(map[string][]byte{"identity.agekey": []byte("AGE-SECRET-KEY-1INVALID")})
+	assert.ErrorContains(t, err, "malformed secret key")
+
+	_, err = ParseKeys(map[string][]byte{"key.asc": []byte("not a key")})
+	assert.ErrorContains(t, err, "failed to read pgp keys from key.asc")
+
+	keys, err := ParseKeys(map[string][]byte{"values.yaml": []byte("a: b")})
+	require.NoError(t, err)
+	assert.Empty(t, keys.age)
+	assert.Empty(t, keys.pgp)
+}
+
+// encryptDocument encrypts a document for the master keys with sops. Keys with the _unencrypted suffix
+// are left in plain text.
+func encryptDocument(t *testing.T, plain string, dataKey []byte, masterKeys ...sopskeys.MasterKey) string {
+	t.Helper()
+
+	store := sopsyaml.NewStore(&config.YAMLStoreConfig{Indent: indent})
+	branches, err := store.LoadPlainFile([]byte(plain))
+	require.NoError(t, err)
+
+	tree := sops.Tree{
+		Branches: branches,
+		Metadata: sops.Metadata{
+			KeyGroups:         []sops.KeyGroup{masterKeys},
+			UnencryptedSuffix: "_unencrypted",
+			Version:           "3.10.2",
+		},
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Encrypt(dataKey, cipher)
+	require.NoError(t, err)
+
+	tree.Metadata.LastModified = time.Now().UTC()
+	tree.Metadata.MessageAuthenticationCode, err = cipher.Encrypt(mac, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	require.NoError(t, err)
+
+	out, err := store.EmitEncryptedFile(tree)
+	require.NoError(t, err)
+
+	return string(out)
+}
+
+// newAgeKey returns a new age identity and the sops master key of its recipient holding the encrypted
+// data key.
+func newAgeKey(t *testing.T, dataKey []byte) (*age.X25519Identity, sopskeys.MasterKey) {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	key, err := sopsage.MasterKeyFromRecipient(identity.Recipient().String())
+	require.NoError(t, err)
+	require.NoError(t, key.Encrypt(dataKey))
+
+	return identity, key
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	return b
+}
@@ -11,6 +11,7 @@ import (
 // MarkNotReady sets the condition status of an Object to `Not Ready`.
 func MarkNotReady(recorder kuberecorder.EventRecorder, obj conditions.Setter, reason, msg string) {
 	conditions.Delete(obj, meta.ReconcilingCondition)
+	conditions.Delete(obj, meta.StalledCondition)
 	conditions.MarkFalse(obj, meta.ReadyCondition, reason, msg, []any{}...)
 	event.New(recorder, obj, nil, eventv1.EventSeverityError, msg, []any{}...)
 }
@@ -27,5 +28,6 @@ func MarkAsStalled(recorder kuberecorder.EventRecorder, obj conditions.Setter, r
 func MarkReady(recorder kuberecorder.EventRecorder, obj conditions.Setter, msg string, messageArgs ...any) {
 	conditions.MarkTrue(obj, meta.ReadyCondition, meta.SucceededReason, msg, messageArgs...)
 	conditions.Delete(obj, meta.ReconcilingCondition)
+	conditions.Delete(obj, meta.StalledCondition)
 	event.New(recorder, obj, nil, eventv1.EventSeverityInfo, msg, messageArgs...)
 }
@@ -0,0 +1,80 @@
+package substitute
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	envLine = regexp.MustCompile(`^(\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=\s*)(.*)$`)
+	envKey  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+)
+
+// envEditor edits dotenv files. The path of a substitution is the name of the variable. Variables which
+// don't exist yet are appended to the file.
+type envEditor struct {
+	lines *lines
+}
+
+func newEnvEditor(content []byte) *envEditor {
+	return &envEditor{lines: splitLines(content)}
+}
+
+func (e *envEditor) set(s Substitution) error {
+	if !envKey.MatchString(s.Path) {
+		return errors.New("invalid variable name " + s.Path)
+	}
+
+	value, err := scalarString(s.Value)
+	if err != nil {
+		return err
+	}
+
+	value = quoteEnv(value)
+	found := false
+
+	for i, line := range e.lines.items {
+		m := envLine.FindStringSubmatch(line)
+		if m == nil || m[2] != s.Path {
+			continue
+		}
+
+		e.lines.items[i] = m[1] + value + envComment(m[3])
+		found = true
+	}
+
+	if !found {
+		e.lines.items = append(e.lines.items, s.Path+"="+value)
+	}
+
+	return nil
+}
+
+func (e *envEditor) bytes() ([]byte, error) {
+	return e.lines.bytes(), nil
+}
+
+// envComment returns the trailing comment of an unquoted value including the whitespace in front of it.
+func envComment(value string) string {
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		return ""
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		return value[i:]
+	}
+
+	return ""
+}
+
+// quoteEnv double quotes values which contain whitespace or characters with a special meaning.
+func quoteEnv(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\r\n\"'`#$\\") {
+		return value
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`, "`", "\\`")
+
+	return `"` + r.Replace(value) + `"`
+}
@@ -0,0 +1,101 @@
+package substitute
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+// globstar matches any number of directories in a file pattern.
+const globstar = "**"
+
+// IsPattern returns true if the file contains glob meta characters.
+func IsPattern(file string) bool {
+	return strings.ContainsAny(file, `*?[\`)
+}
+
+// Files returns the files below root which match the pattern, sorted lexically. The pattern uses the syntax
+// of path.Match, additionally ** matches any number of directories. If a selector is given only YAML files
+// containing at least one matching document are returned.
+func Files(root, pattern string, selector *configdata.DocumentSelector) ([]string, error) {
+	pattern = path.Clean(strings.TrimPrefix(pattern, "/"))
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid file pattern %s: %w", pattern, err)
+	}
+
+	patternSegments := strings.Split(pattern, "/")
+
+	var files []string
+	if err := fs.WalkDir(os.DirFS(root), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !matchSegments(patternSegments, strings.Split(name, "/")) {
+			return nil
+		}
+
+		if selector != nil && !containsDocument(root, name, selector) {
+			return nil
+		}
+
+		files = append(files, name)
+
+		return nil
+	}); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to match files with pattern %s: %w", pattern, err)
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == globstar {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// containsDocument returns true if the file contains a document matching the selector. Files which
+// aren't YAML can't contain a selected document.
+func containsDocument(root, name string, selector *configdata.DocumentSelector) bool {
+	docs, err := readDocuments(root, name)
+	if err != nil {
+		return false
+	}
+
+	for _, doc := range docs {
+		if matchesSelector(doc, selector) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+package substitute
+
+import "strings"
+
+// lines holds the lines of a text file together with its line ending, so line based editors can write
+// the file back unchanged apart from the edited lines.
+type lines struct {
+	items []string
+	eol   string
+}
+
+func splitLines(content []byte) *lines {
+	text := string(content)
+
+	eol := "\n"
+	if strings.Contains(text, "\r\n") {
+		eol = "\r\n"
+	}
+
+	text = strings.TrimSuffix(text, eol)
+	if text == "" {
+		return &lines{eol: eol}
+	}
+
+	return &lines{items: strings.Split(text, eol), eol: eol}
+}
+
+func (l *lines) bytes() []byte {
+	if len(l.items) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(l.items, l.eol) + l.eol)
+}
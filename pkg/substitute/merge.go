@@ -0,0 +1,63 @@
+package substitute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Merge deep merges value into every document of a YAML or JSON file below root. Maps are merged key by
+// key, any other value replaces the value in the document, which is how helm merges values. Comments of
+// the file are kept.
+func Merge(root, file string, value json.RawMessage) error {
+	node, err := valueNode(value)
+	if err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return errors.New("only maps can be merged into a file")
+	}
+
+	if err := patchFile(root, file, Target{File: file}, mergeTransformation(node)); err != nil {
+		return fmt.Errorf("failed to merge value into file %s: %w", file, err)
+	}
+
+	return nil
+}
+
+func mergeTransformation(value *yaml.Node) transformation {
+	return func(doc *yaml.Node) (*yaml.Node, error) {
+		if len(doc.Content) == 0 || isEmpty(doc.Content[0]) {
+			doc.Content = []*yaml.Node{value}
+
+			return doc, nil
+		}
+
+		mergeNodes(doc.Content[0], value)
+
+		return doc, nil
+	}
+}
+
+// mergeNodes merges the value into the node.
+func mergeNodes(node, value *yaml.Node) {
+	if node.Kind != yaml.MappingNode || value.Kind != yaml.MappingNode {
+		replace(node, value)
+
+		return
+	}
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, v := value.Content[i], value.Content[i+1]
+		if existing := lookup(node, key.Value); existing != nil {
+			mergeNodes(existing, v)
+
+			continue
+		}
+
+		node.Content = append(node.Content, key, v)
+	}
+}
@@ -0,0 +1,49 @@
+package substitute
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"podinfo/values.yaml": `# Default values for podinfo.
+replicaCount: 1 # scaled by hpa
+image:
+  repository: ghcr.io/stefanprodan/podinfo
+  tag: 6.0.0
+ports: [9898]
+`,
+		"empty.yaml": "",
+	})
+
+	err := Merge(root, "podinfo/values.yaml", json.RawMessage(`{"image": {"tag": "6.1.0"}, "ports": [8080], "ingress": {"enabled": true}}`))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(root, "podinfo", "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# Default values for podinfo.
+replicaCount: 1 # scaled by hpa
+image:
+  repository: ghcr.io/stefanprodan/podinfo
+  tag: 6.1.0
+ports:
+  - 8080
+ingress:
+  enabled: true
+`, string(content))
+
+	require.NoError(t, Merge(root, "empty.yaml", json.RawMessage(`{"replicaCount": 3}`)))
+
+	content, err = os.ReadFile(filepath.Join(root, "empty.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "replicaCount: 3\n", string(content))
+
+	err = Merge(root, "empty.yaml", json.RawMessage(`[1]`))
+	assert.ErrorContains(t, err, "only maps can be merged into a file")
+}
@@ -0,0 +1,183 @@
+package substitute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"gopkg.in/yaml.v3"
+)
+
+// Patch edits the documents of the files matching its target, either with RFC 6902 JSON patch operations
+// or with a yq expression whose result replaces the document. Without a document selector every document
+// of a file is edited. Documents edited by a JSON patch lose their comments.
+type Patch struct {
+	Name       string
+	Target     Target
+	Operations json.RawMessage
+	Expression string
+}
+
+// transformation edits a single document.
+type transformation func(doc *yaml.Node) (*yaml.Node, error)
+
+// ApplyPatch edits the YAML and JSON files below root matching the target of the patch and returns their
+// names. It fails if no file matches, so a patch is never lost silently.
+func ApplyPatch(root string, p Patch) ([]string, error) {
+	transform, err := newTransformation(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch %s: %w", p.Name, err)
+	}
+
+	files, err := Files(root, p.Target.File, p.Target.Document)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("patch %s matches no files", p.Name)
+	}
+
+	for _, file := range files {
+		if err := patchFile(root, file, p.Target, transform); err != nil {
+			return nil, fmt.Errorf("failed to apply patch %s to file %s: %w", p.Name, file, err)
+		}
+	}
+
+	return files, nil
+}
+
+func newTransformation(p Patch) (transformation, error) {
+	switch {
+	case len(p.Operations) > 0 && p.Expression != "":
+		return nil, errors.New("operations and expression are mutually exclusive")
+	case len(p.Operations) > 0:
+		patch, err := jsonpatch.DecodePatch(p.Operations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode json patch: %w", err)
+		}
+
+		return jsonPatchTransformation(patch), nil
+	case p.Expression != "":
+		return yqTransformation(p.Expression), nil
+	}
+
+	return nil, errors.New("either operations or an expression is required")
+}
+
+func patchFile(root, file string, target Target, transform transformation) error {
+	format := target.Format
+	if format == "" {
+		format = DetectFormat(file)
+	}
+
+	if format != FormatYAML && format != FormatJSON {
+		return fmt.Errorf("patches are only supported for yaml and json files, got %s", format)
+	}
+
+	filePath, err := securejoin.SecureJoin(root, file)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	e, err := newYAMLEditor(content, format == FormatJSON)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s file: %w", format, err)
+	}
+
+	for i, doc := range e.docs {
+		if !matchesSelector(doc, target.Document) {
+			continue
+		}
+
+		if e.docs[i], err = transform(doc); err != nil {
+			return err
+		}
+	}
+
+	result, err := e.bytes()
+	if err != nil {
+		return fmt.Errorf("failed to encode %s file: %w", format, err)
+	}
+
+	return os.WriteFile(filePath, result, info.Mode())
+}
+
+func jsonPatchTransformation(patch jsonpatch.Patch) transformation {
+	return func(doc *yaml.Node) (*yaml.Node, error) {
+		var value any = orderedValue{}
+		if len(doc.Content) > 0 {
+			value = orderedValue{node: doc.Content[0]}
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		patched, err := patch.Apply(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply json patch: %w", err)
+		}
+
+		result, err := singleDocument(patched)
+		if err != nil {
+			return nil, err
+		}
+
+		// the patched document is JSON, encode it like the other documents
+		resetStyle(result)
+
+		return result, nil
+	}
+}
+
+func yqTransformation(expression string) transformation {
+	return func(doc *yaml.Node) (*yaml.Node, error) {
+		content, err := encodeYAML([]*yaml.Node{doc})
+		if err != nil {
+			return nil, err
+		}
+
+		preferences := yqlib.NewDefaultYamlPreferences()
+		result, err := yqlib.NewStringEvaluator().Evaluate(
+			expression,
+			string(content),
+			yqlib.NewYamlEncoder(preferences),
+			yqlib.NewYamlDecoder(preferences),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate expression: %w", err)
+		}
+
+		return singleDocument([]byte(result))
+	}
+}
+
+// singleDocument parses the result of a transformation, which must be exactly one document.
+func singleDocument(content []byte) (*yaml.Node, error) {
+	docs, err := parseDocuments(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+
+	if len(docs) != 1 || len(docs[0].Content) == 0 {
+		return nil, fmt.Errorf("expected a single document as result, got %d", len(docs))
+	}
+
+	return docs[0], nil
+}
@@ -0,0 +1,195 @@
+package substitute
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	glog "gopkg.in/op/go-logging.v1"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+// yqlib logs every evaluation step by default.
+func init() {
+	glog.SetLevel(glog.WARNING, "yq-lib")
+}
+
+const sidecarDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  template:
+    spec:
+      containers:
+        - name: backend
+          image: backend:1.0.0
+          args:
+            - --port=8080
+        - name: sidecar
+          image: sidecar:1.0.0
+`
+
+func TestApplyPatchJSONPatch(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"deploy.yaml":            multiDocument,
+		"manifests/sidecar.yaml": sidecarDeployment,
+		"config.json":            `{"replicas": 1}`,
+	})
+
+	files, err := ApplyPatch(root, Patch{
+		Name: "remove-sidecar",
+		Target: Target{
+			File:     "manifests/*.yaml",
+			Document: &configdata.DocumentSelector{Kind: "Deployment", Name: "backend"},
+		},
+		Operations: json.RawMessage(`[
+			{"op": "remove", "path": "/spec/template/spec/containers/1"},
+			{"op": "add", "path": "/spec/template/spec/containers/0/args/-", "value": "--verbose"}
+		]`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manifests/sidecar.yaml"}, files)
+
+	content, err := os.ReadFile(filepath.Join(root, "manifests", "sidecar.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  template:
+    spec:
+      containers:
+        - name: backend
+          image: backend:1.0.0
+          args:
+            - --port=8080
+            - --verbose
+`, string(content))
+
+	files, err = ApplyPatch(root, Patch{
+		Name:       "json",
+		Target:     Target{File: "config.json"},
+		Operations: json.RawMessage(`[{"op": "replace", "path": "/replicas", "value": 3}]`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config.json"}, files)
+
+	content, err = os.ReadFile(filepath.Join(root, "config.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"replicas": 3}`, string(content))
+}
+
+func TestApplyPatchExpression(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"deploy.yaml": multiDocument,
+	})
+
+	files, err := ApplyPatch(root, Patch{
+		Name:       "scale-backend",
+		Target:     Target{File: "deploy.yaml"},
+		Expression: `(select(.metadata.name == "backend") | .spec.replicas) = 3`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy.yaml"}, files)
+
+	content, err := os.ReadFile(filepath.Join(root, "deploy.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 1 # scaled by hpa
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: backend
+          image: backend:1.0.0
+`, string(content))
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"deploy.yaml": multiDocument,
+		"app.toml":    "name = \"app\"\n",
+	})
+
+	testCases := []struct {
+		name  string
+		patch Patch
+		err   string
+	}{
+		{
+			name:  "no files",
+			patch: Patch{Name: "missing", Target: Target{File: "missing.yaml"}, Expression: "."},
+			err:   "patch missing matches no files",
+		},
+		{
+			name: "no document",
+			patch: Patch{
+				Name:       "service",
+				Target:     DocumentTarget("deploy.yaml", "Service", ""),
+				Expression: ".",
+			},
+			err: "patch service matches no files",
+		},
+		{
+			name:  "no mutation",
+			patch: Patch{Name: "empty", Target: Target{File: "deploy.yaml"}},
+			err:   "either operations or an expression is required",
+		},
+		{
+			name: "both mutations",
+			patch: Patch{
+				Name:       "both",
+				Target:     Target{File: "deploy.yaml"},
+				Operations: json.RawMessage(`[]`),
+				Expression: ".",
+			},
+			err: "operations and expression are mutually exclusive",
+		},
+		{
+			name: "failing operation",
+			patch: Patch{
+				Name:       "test",
+				Target:     Target{File: "deploy.yaml"},
+				Operations: json.RawMessage(`[{"op": "test", "path": "/kind", "value": "Service"}]`),
+			},
+			err: "failed to apply json patch",
+		},
+		{
+			name:  "invalid expression",
+			patch: Patch{Name: "invalid", Target: Target{File: "deploy.yaml"}, Expression: ".spec |"},
+			err:   "failed to evaluate expression",
+		},
+		{
+			name:  "unsupported format",
+			patch: Patch{Name: "toml", Target: Target{File: "app.toml"}, Expression: "."},
+			err:   "patches are only supported for yaml and json files, got toml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ApplyPatch(root, tc.patch)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, "deploy.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, multiDocument, string(content))
+}
@@ -0,0 +1,229 @@
+package substitute
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// segment is a single step of a value path. It is either a key of a map or an index of a list.
+type segment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// parsePath splits a path such as spec.template.spec.containers[0].image into its segments. Keys containing
+// dots or brackets are quoted, either as a segment, e.g. metadata.annotations."example.com/foo", or in
+// brackets, e.g. metadata.annotations["example.com/foo"]. Quotes and backslashes in quoted keys are escaped
+// with a backslash.
+func parsePath(path string) ([]segment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path must not be empty")
+	}
+
+	var (
+		segments []segment
+		rest     = path
+	)
+
+	for {
+		count := len(segments)
+
+		if rest != "" && isQuote(rest[0]) {
+			key, remainder, err := unquote(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %s: %w", path, err)
+			}
+
+			segments = append(segments, segment{key: key, isKey: true})
+			rest = remainder
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			if end > 0 {
+				segments = append(segments, segment{key: rest[:end], isKey: true})
+			}
+
+			rest = rest[end:]
+		}
+
+		for strings.HasPrefix(rest, "[") {
+			seg, remainder, err := parseBracket(rest[1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %s: %w", path, err)
+			}
+
+			segments = append(segments, seg)
+			rest = remainder
+		}
+
+		if len(segments) == count {
+			return nil, fmt.Errorf("invalid path %s: empty key", path)
+		}
+
+		if rest == "" {
+			return segments, nil
+		}
+
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("invalid path %s: unexpected %s", path, rest)
+		}
+
+		rest = rest[1:]
+	}
+}
+
+// parseBracket parses the content of brackets, an index or a quoted key, up to the closing bracket.
+func parseBracket(s string) (segment, string, error) {
+	if s != "" && isQuote(s[0]) {
+		key, rest, err := unquote(s)
+		if err != nil {
+			return segment{}, "", err
+		}
+
+		if !strings.HasPrefix(rest, "]") {
+			return segment{}, "", fmt.Errorf("missing ]")
+		}
+
+		return segment{key: key, isKey: true}, rest[1:], nil
+	}
+
+	idx, rest, ok := strings.Cut(s, "]")
+	if !ok {
+		return segment{}, "", fmt.Errorf("missing ]")
+	}
+
+	i, err := strconv.Atoi(idx)
+	if err != nil || i < 0 {
+		return segment{}, "", fmt.Errorf("invalid index %s", idx)
+	}
+
+	return segment{index: i}, rest, nil
+}
+
+func isQuote(c byte) bool {
+	return c == '"' || c == '\''
+}
+
+// unquote reads the quoted key at the start of s and returns it with the rest of s.
+func unquote(s string) (string, string, error) {
+	quote := s[0]
+
+	var key strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case quote:
+			return key.String(), s[i+1:], nil
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == quote || s[i+1] == '\\') {
+				i++
+			}
+		}
+
+		key.WriteByte(s[i])
+	}
+
+	return "", "", fmt.Errorf("missing closing %c", quote)
+}
+
+// setPath replaces the value at the path in the document. Missing map keys are created, lists can be
+// extended by a single element.
+func setPath(doc *yaml.Node, path string, value *yaml.Node) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{newContainer(segments[0])}
+	}
+
+	node := doc.Content[0]
+	for i, seg := range segments {
+		last := i == len(segments)-1
+
+		next, err := child(node, seg, path)
+		if err != nil {
+			return err
+		}
+
+		if last {
+			replace(next, value)
+
+			return nil
+		}
+
+		if isEmpty(next) {
+			*next = *newContainer(segments[i+1])
+		}
+
+		node = next
+	}
+
+	return nil
+}
+
+// child returns the node for the segment, creating it if necessary.
+func child(node *yaml.Node, seg segment, path string) (*yaml.Node, error) {
+	if isEmpty(node) {
+		*node = *newContainer(seg)
+	}
+
+	if seg.isKey {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("failed to set %s: %s is not a map", path, seg.key)
+		}
+
+		if value := lookup(node, seg.key); value != nil {
+			return value, nil
+		}
+
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: seg.key}, value)
+
+		return value, nil
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("failed to set %s: index %d used on a value which is not a list", path, seg.index)
+	}
+
+	switch {
+	case seg.index < len(node.Content):
+		return node.Content[seg.index], nil
+	case seg.index == len(node.Content):
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		node.Content = append(node.Content, value)
+
+		return value, nil
+	default:
+		return nil, fmt.Errorf("failed to set %s: index %d out of range", path, seg.index)
+	}
+}
+
+func newContainer(seg segment) *yaml.Node {
+	if seg.isKey {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+}
+
+func isEmpty(node *yaml.Node) bool {
+	return node.Kind == 0 || (node.Kind == yaml.ScalarNode && node.Tag == "!!null")
+}
+
+// replace overwrites the node with the value, but keeps the comments of the original node.
+func replace(node, value *yaml.Node) {
+	head, line, foot := node.HeadComment, node.LineComment, node.FootComment
+	*node = *value
+	node.HeadComment, node.LineComment, node.FootComment = head, line, foot
+}
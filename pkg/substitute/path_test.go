@@ -0,0 +1,119 @@
+package substitute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParsePath(t *testing.T) {
+	key := func(k string) segment { return segment{key: k, isKey: true} }
+	index := func(i int) segment { return segment{index: i} }
+
+	testCases := []struct {
+		name        string
+		path        string
+		expected    []segment
+		expectedErr string
+	}{
+		{
+			name:     "keys and indexes",
+			path:     "spec.containers[0].ports[1][2].name",
+			expected: []segment{key("spec"), key("containers"), index(0), key("ports"), index(1), index(2), key("name")},
+		},
+		{
+			name:     "leading index",
+			path:     "[0].name",
+			expected: []segment{index(0), key("name")},
+		},
+		{
+			name:     "quoted segment",
+			path:     `metadata.annotations."example.com/foo"`,
+			expected: []segment{key("metadata"), key("annotations"), key("example.com/foo")},
+		},
+		{
+			name:     "single quoted segment followed by a key",
+			path:     `data.'app.properties'.value`,
+			expected: []segment{key("data"), key("app.properties"), key("value")},
+		},
+		{
+			name:     "bracketed key",
+			path:     `metadata.annotations["example.com/foo"]`,
+			expected: []segment{key("metadata"), key("annotations"), key("example.com/foo")},
+		},
+		{
+			name:     "bracketed key followed by an index",
+			path:     `spec['a.b'][0]`,
+			expected: []segment{key("spec"), key("a.b"), index(0)},
+		},
+		{
+			name:     "escaped quote",
+			path:     `data."say \"hi\"".value`,
+			expected: []segment{key("data"), key(`say "hi"`), key("value")},
+		},
+		{
+			name:        "empty",
+			path:        "",
+			expectedErr: "path must not be empty",
+		},
+		{
+			name:        "empty key",
+			path:        "spec..replicas",
+			expectedErr: "empty key",
+		},
+		{
+			name:        "trailing dot",
+			path:        "spec.",
+			expectedErr: "empty key",
+		},
+		{
+			name:        "missing closing quote",
+			path:        `metadata."example.com`,
+			expectedErr: `missing closing "`,
+		},
+		{
+			name:        "missing closing bracket",
+			path:        `metadata["example.com"`,
+			expectedErr: "missing ]",
+		},
+		{
+			name:        "invalid index",
+			path:        "spec[a]",
+			expectedErr: "invalid index a",
+		},
+		{
+			name:        "text after quoted key",
+			path:        `metadata."a"b`,
+			expectedErr: "unexpected b",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			segments, err := parsePath(tc.path)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, segments)
+		})
+	}
+}
+
+func TestSetPathDottedKey(t *testing.T) {
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("metadata:\n  annotations:\n    example.com/foo: a\n"), &doc))
+
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "b"}
+	require.NoError(t, setPath(&doc, `metadata.annotations["example.com/foo"]`, value))
+	require.NoError(t, setPath(&doc, `metadata.annotations."example.com/bar"`, value))
+
+	content, err := yaml.Marshal(&doc)
+	require.NoError(t, err)
+	assert.Equal(t, "metadata:\n    annotations:\n        example.com/foo: b\n        example.com/bar: b\n", string(content))
+}
@@ -0,0 +1,157 @@
+package substitute
+
+import (
+	"strings"
+)
+
+// propertiesEditor edits Java properties files. The path of a substitution is the key of the property,
+// dots are part of the key. Properties which don't exist yet are appended to the file.
+type propertiesEditor struct {
+	lines *lines
+}
+
+func newPropertiesEditor(content []byte) *propertiesEditor {
+	return &propertiesEditor{lines: splitLines(content)}
+}
+
+func (e *propertiesEditor) set(s Substitution) error {
+	value, err := scalarString(s.Value)
+	if err != nil {
+		return err
+	}
+
+	value = escapeProperty(value, false)
+	found := false
+
+	for i := 0; i < len(e.lines.items); i++ {
+		first := i
+		logical := e.lines.items[i]
+
+		// comments never continue on the next line, even if they end with a backslash
+		trimmed := strings.TrimLeft(logical, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+
+		// a line ending with an odd number of backslashes continues on the next line
+		for continues(e.lines.items[i]) && i+1 < len(e.lines.items) {
+			i++
+		}
+
+		key, prefix := splitProperty(logical)
+		if key != s.Path {
+			continue
+		}
+
+		items := append([]string{}, e.lines.items[:first]...)
+		items = append(items, prefix+value)
+		items = append(items, e.lines.items[i+1:]...)
+		e.lines.items = items
+		i = first
+		found = true
+	}
+
+	if !found {
+		e.lines.items = append(e.lines.items, escapeProperty(s.Path, true)+"="+value)
+	}
+
+	return nil
+}
+
+func (e *propertiesEditor) bytes() ([]byte, error) {
+	return e.lines.bytes(), nil
+}
+
+func continues(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+
+	return n%2 == 1
+}
+
+// splitProperty returns the unescaped key of a property line and the part of the line in front of the
+// value, i.e. the key including the separator and surrounding whitespace.
+func splitProperty(line string) (string, string) {
+	i := len(line) - len(strings.TrimLeft(line, " \t\f"))
+	key := &strings.Builder{}
+
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' && i+1 < len(line) {
+			i++
+			key.WriteByte(unescapeProperty(line[i]))
+
+			continue
+		}
+
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+
+		key.WriteByte(c)
+	}
+
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t' || line[i] == '\f') {
+		i++
+	}
+
+	if i < len(line) && (line[i] == '=' || line[i] == ':') {
+		i++
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t' || line[i] == '\f') {
+			i++
+		}
+	}
+
+	return key.String(), line[:i]
+}
+
+func unescapeProperty(c byte) byte {
+	switch c {
+	case 't':
+		return '\t'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 'f':
+		return '\f'
+	default:
+		return c
+	}
+}
+
+// escapeProperty escapes a key or value. Separators only need escaping in keys, leading whitespace
+// only in values.
+func escapeProperty(s string, isKey bool) string {
+	b := &strings.Builder{}
+
+	for i, c := range s {
+		switch c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			if isKey {
+				b.WriteByte('\\')
+			}
+
+			b.WriteRune(c)
+		case ' ':
+			if isKey || i == 0 {
+				b.WriteByte('\\')
+			}
+
+			b.WriteRune(c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,233 @@
+package substitute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+// Supported file formats.
+const (
+	FormatYAML       = "yaml"
+	FormatJSON       = "json"
+	FormatTOML       = "toml"
+	FormatEnv        = "env"
+	FormatProperties = "properties"
+)
+
+// Substitution replaces the value at a path in a file. If a document selector is given, the value is
+// replaced in every matching document of the file, otherwise in the first document. The format of the
+// file is derived from its extension unless it is set explicitly.
+type Substitution struct {
+	Name     string                       `json:"name"`
+	File     string                       `json:"file"`
+	Path     string                       `json:"path"`
+	Value    json.RawMessage              `json:"value"`
+	Document *configdata.DocumentSelector `json:"document,omitempty"`
+	Format   string                       `json:"format,omitempty"`
+}
+
+// Substitutions is a list of substitutions which are applied in order.
+type Substitutions []Substitution
+
+// Add appends a substitution of the given value to the list.
+func (s *Substitutions) Add(name string, target Target, path string, value any) error {
+	data, ok := value.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return fmt.Errorf("failed to marshal value of substitution %s: %w", name, err)
+		}
+	}
+
+	*s = append(*s, Substitution{
+		Name:     name,
+		File:     target.File,
+		Path:     path,
+		Value:    data,
+		Document: target.Document,
+		Format:   target.Format,
+	})
+
+	return nil
+}
+
+// Target describes the file and documents a substitution applies to.
+type Target struct {
+	File     string
+	Document *configdata.DocumentSelector
+	Format   string
+}
+
+// DocumentTarget returns the target of the documents of a file with the given kind and name. Empty fields
+// match any document.
+func DocumentTarget(file, kind, name string) Target {
+	return Target{File: file, Document: &configdata.DocumentSelector{Kind: kind, Name: name}}
+}
+
+// editor modifies the content of a file of a specific format.
+type editor interface {
+	set(s Substitution) error
+	bytes() ([]byte, error)
+}
+
+// DetectFormat returns the format of a file based on its name. Files without a known extension are YAML.
+func DetectFormat(file string) string {
+	base := strings.ToLower(path.Base(file))
+
+	switch {
+	case strings.HasSuffix(base, ".json"):
+		return FormatJSON
+	case strings.HasSuffix(base, ".toml"):
+		return FormatTOML
+	case strings.HasSuffix(base, ".properties"):
+		return FormatProperties
+	case base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env"):
+		return FormatEnv
+	default:
+		return FormatYAML
+	}
+}
+
+// Apply performs the substitutions on the files below root. Files are only written once all of their
+// substitutions succeeded.
+func Apply(root string, substitutions Substitutions) error {
+	var files []string
+	byFile := make(map[string]Substitutions)
+
+	for _, s := range substitutions {
+		if _, ok := byFile[s.File]; !ok {
+			files = append(files, s.File)
+		}
+
+		byFile[s.File] = append(byFile[s.File], s)
+	}
+
+	for _, file := range files {
+		if err := applyToFile(root, file, byFile[file]); err != nil {
+			return fmt.Errorf("failed to substitute values in file %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+func applyToFile(root, file string, substitutions Substitutions) error {
+	format, err := fileFormat(file, substitutions)
+	if err != nil {
+		return err
+	}
+
+	filePath, err := securejoin.SecureJoin(root, file)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	e, err := newEditor(format, content)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s file: %w", format, err)
+	}
+
+	for _, s := range substitutions {
+		if s.Document != nil && format != FormatYAML {
+			return fmt.Errorf("substitution %s: document selectors are only supported for yaml files", s.Name)
+		}
+
+		if err := e.set(s); err != nil {
+			if errors.Is(err, errNoDocument) {
+				return fmt.Errorf("no document matches substitution %s", s.Name)
+			}
+
+			return fmt.Errorf("failed to apply substitution %s: %w", s.Name, err)
+		}
+	}
+
+	result, err := e.bytes()
+	if err != nil {
+		return fmt.Errorf("failed to encode %s file: %w", format, err)
+	}
+
+	return os.WriteFile(filePath, result, info.Mode())
+}
+
+// fileFormat returns the format explicitly requested by the substitutions or the one derived from the
+// file name. Substitutions of the same file must not request different formats.
+func fileFormat(file string, substitutions Substitutions) (string, error) {
+	format := ""
+	for _, s := range substitutions {
+		if s.Format == "" {
+			continue
+		}
+
+		if format != "" && format != s.Format {
+			return "", fmt.Errorf("conflicting formats %s and %s requested", format, s.Format)
+		}
+
+		format = s.Format
+	}
+
+	if format == "" {
+		format = DetectFormat(file)
+	}
+
+	return format, nil
+}
+
+func newEditor(format string, content []byte) (editor, error) {
+	switch format {
+	case FormatYAML:
+		return newYAMLEditor(content, false)
+	case FormatJSON:
+		return newYAMLEditor(content, true)
+	case FormatTOML:
+		return newTOMLEditor(content)
+	case FormatEnv:
+		return newEnvEditor(content), nil
+	case FormatProperties:
+		return newPropertiesEditor(content), nil
+	default:
+		return nil, errors.New("unsupported format")
+	}
+}
+
+// scalarString returns the string representation of a JSON scalar. Lists and objects are returned in
+// their compact JSON form.
+func scalarString(value json.RawMessage) (string, error) {
+	var v any
+	if err := json.Unmarshal(value, &v); err != nil {
+		return "", err
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case map[string]any, []any:
+		data, err := json.Marshal(x)
+		if err != nil {
+			return "", err
+		}
+
+		return string(data), nil
+	default:
+		return strings.TrimSpace(string(value)), nil
+	}
+}
@@ -0,0 +1,414 @@
+package substitute
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+const multiDocument = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 1 # scaled by hpa
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: backend
+          image: backend:1.0.0
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	return root
+}
+
+func TestFiles(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"deploy.yaml":                "kind: Deployment\nmetadata:\n  name: frontend\n",
+		"manifests/a/deploy.yaml":    multiDocument,
+		"manifests/b/c/service.yaml": "kind: Service\nmetadata:\n  name: backend\n",
+		"manifests/readme.md":        "# manifests",
+	})
+
+	testCases := []struct {
+		name     string
+		pattern  string
+		selector *configdata.DocumentSelector
+		expected []string
+	}{
+		{
+			name:     "single file",
+			pattern:  "deploy.yaml",
+			expected: []string{"deploy.yaml"},
+		},
+		{
+			name:     "glob",
+			pattern:  "manifests/*/*.yaml",
+			expected: []string{"manifests/a/deploy.yaml"},
+		},
+		{
+			name:     "globstar",
+			pattern:  "**/*.yaml",
+			expected: []string{"deploy.yaml", "manifests/a/deploy.yaml", "manifests/b/c/service.yaml"},
+		},
+		{
+			name:     "selector",
+			pattern:  "**/*.yaml",
+			selector: &configdata.DocumentSelector{Kind: "Deployment", Name: "backend"},
+			expected: []string{"manifests/a/deploy.yaml"},
+		},
+		{
+			name:    "no match",
+			pattern: "**/*.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := Files(root, tc.pattern, tc.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, files)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"deploy.yaml": multiDocument,
+		"config.json": `{"replicas": 1}`,
+	})
+
+	var substitutions Substitutions
+	require.NoError(t, substitutions.Add("replicas", Target{File: "deploy.yaml"}, "spec.replicas", 3))
+	require.NoError(t, substitutions.Add("image",
+		Target{File: "deploy.yaml", Document: &configdata.DocumentSelector{Kind: "Deployment", Name: "backend"}},
+		"spec.template.spec.containers[0].image", "ghcr.io/backend:1.0.0"))
+	require.NoError(t, substitutions.Add("labels",
+		Target{File: "deploy.yaml", Document: &configdata.DocumentSelector{Name: "backend"}},
+		"metadata.labels", map[string]string{"app": "backend"}))
+	require.NoError(t, substitutions.Add("json", Target{File: "config.json"}, "image.tag", "1.0.0"))
+
+	require.NoError(t, Apply(root, substitutions))
+
+	content, err := os.ReadFile(filepath.Join(root, "deploy.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 3 # scaled by hpa
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  labels:
+    app: backend
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: backend
+          image: ghcr.io/backend:1.0.0
+`, string(content))
+
+	content, err = os.ReadFile(filepath.Join(root, "config.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"replicas": 1, "image": {"tag": "1.0.0"}}`, string(content))
+}
+
+func TestApplyErrors(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"deploy.yaml": multiDocument,
+	})
+
+	testCases := []struct {
+		name         string
+		substitution Substitution
+		expectedErr  string
+	}{
+		{
+			name:         "missing file",
+			substitution: Substitution{Name: "a", File: "missing.yaml", Path: "a", Value: []byte(`1`)},
+			expectedErr:  "no such file or directory",
+		},
+		{
+			name: "no document selected",
+			substitution: Substitution{Name: "a", File: "deploy.yaml", Path: "a", Value: []byte(`1`),
+				Document: &configdata.DocumentSelector{Kind: "Service"}},
+			expectedErr: "no document matches substitution a",
+		},
+		{
+			name:         "index out of range",
+			substitution: Substitution{Name: "a", File: "deploy.yaml", Path: "spec.replicas[2]", Value: []byte(`1`)},
+			expectedErr:  "index 2 used on a value which is not a list",
+		},
+		{
+			name:         "invalid path",
+			substitution: Substitution{Name: "a", File: "deploy.yaml", Path: "spec..replicas", Value: []byte(`1`)},
+			expectedErr:  "empty key",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorContains(t, Apply(root, Substitutions{tc.substitution}), tc.expectedErr)
+		})
+	}
+}
+
+func TestApplyFormats(t *testing.T) {
+	testCases := []struct {
+		name          string
+		file          string
+		content       string
+		format        string
+		substitutions map[string]any
+		expected      string
+	}{
+		{
+			name:    "json keeps order and indentation",
+			file:    "config.json",
+			content: "{\n    \"name\": \"app\",\n    \"image\": {\n        \"tag\": \"0.1.0\"\n    },\n    \"ratio\": 1.0\n}\n",
+			substitutions: map[string]any{
+				"image.tag":  "1.0.0",
+				"image.pull": "<always>",
+			},
+			expected: "{\n    \"name\": \"app\",\n    \"image\": {\n        \"tag\": \"1.0.0\",\n        \"pull\": \"<always>\"\n    },\n    \"ratio\": 1.0\n}\n",
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `# service configuration
+name = "app" # the name
+
+[image]
+tag = "0.1.0"
+ports = [
+  80,  # http
+  443,
+]
+"example.com/digest" = "sha256:a" # pinned
+motd = """
+welcome
+"""
+# end of image
+
+[[servers]]
+host = "a"
+
+[[servers]]
+host = "b"
+`,
+			substitutions: map[string]any{
+				"image.tag":                  "1.0.0",
+				"image.ports":                []int{8080},
+				"image.pull":                 "always",
+				`image."example.com/digest"`: "sha256:b",
+				"image.motd":                 "hello",
+				"image.labels":               map[string]string{"app.kubernetes.io/name": "app"},
+				"servers[1].host":            "c",
+				"replicas":                   2,
+				"limits.cpu":                 "100m",
+			},
+			expected: `# service configuration
+name = "app" # the name
+limits.cpu = '100m'
+replicas = 2
+
+[image]
+tag = '1.0.0'
+ports = [8080]
+"example.com/digest" = 'sha256:b' # pinned
+motd = 'hello'
+labels = {'app.kubernetes.io/name' = 'app'}
+pull = 'always'
+# end of image
+
+[[servers]]
+host = "a"
+
+[[servers]]
+host = 'c'
+`,
+		},
+		{
+			name: "env",
+			file: "app/.env",
+			content: `# database
+export DB_HOST=localhost # local
+DB_PASSWORD='secret'
+`,
+			substitutions: map[string]any{
+				"DB_HOST":     "db.example.com",
+				"DB_PASSWORD": "s3cr3t $pass",
+				"DB_PORT":     5432,
+			},
+			expected: `# database
+export DB_HOST=db.example.com # local
+DB_PASSWORD="s3cr3t \$pass"
+DB_PORT=5432
+`,
+		},
+		{
+			name: "properties",
+			file: "application.properties",
+			content: `# server
+server.port = 8080
+server.hosts: a,\
+  b
+`,
+			substitutions: map[string]any{
+				"server.port":  9090,
+				"server.hosts": "c",
+				"app name":     " spaced",
+			},
+			expected: `# server
+server.port = 9090
+server.hosts: c
+app\ name=\ spaced
+`,
+		},
+		{
+			name: "properties comment ending with a backslash",
+			file: "application.properties",
+			content: `# path on windows: C:\
+server.port = 8080
+! also a comment \
+server.host = localhost
+`,
+			substitutions: map[string]any{
+				"server.port": 9090,
+				"server.host": "example.com",
+			},
+			expected: `# path on windows: C:\
+server.port = 9090
+! also a comment \
+server.host = example.com
+`,
+		},
+		{
+			name:          "explicit format",
+			file:          "config",
+			content:       "KEY=a\n",
+			format:        FormatEnv,
+			substitutions: map[string]any{"KEY": "b"},
+			expected:      "KEY=b\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := writeFiles(t, map[string]string{tc.file: tc.content})
+
+			paths := make([]string, 0, len(tc.substitutions))
+			for p := range tc.substitutions {
+				paths = append(paths, p)
+			}
+
+			sort.Strings(paths)
+
+			var substitutions Substitutions
+			for _, p := range paths {
+				require.NoError(t, substitutions.Add(p, Target{File: tc.file, Format: tc.format}, p, tc.substitutions[p]))
+			}
+
+			require.NoError(t, Apply(root, substitutions))
+
+			content, err := os.ReadFile(filepath.Join(root, tc.file))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(content))
+		})
+	}
+}
+
+func TestApplyFormatErrors(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"config.toml": "[image]\ntag = \"0.1.0\"\nports = [80]\n",
+		".env":        "KEY=a\n",
+	})
+
+	testCases := []struct {
+		name         string
+		substitution Substitution
+		expectedErr  string
+	}{
+		{
+			name:         "toml null",
+			substitution: Substitution{Name: "a", File: "config.toml", Path: "image.tag", Value: []byte(`null`)},
+			expectedErr:  "toml does not support null values",
+		},
+		{
+			name:         "toml inside value",
+			substitution: Substitution{Name: "a", File: "config.toml", Path: "image.ports[0]", Value: []byte(`1`)},
+			expectedErr:  "cannot set image.ports[0] inside the value of image.ports",
+		},
+		{
+			name:         "toml table",
+			substitution: Substitution{Name: "a", File: "config.toml", Path: "image", Value: []byte(`1`)},
+			expectedErr:  "image is a table",
+		},
+		{
+			name: "selector on env file",
+			substitution: Substitution{Name: "a", File: ".env", Path: "KEY", Value: []byte(`1`),
+				Document: &configdata.DocumentSelector{Kind: "Deployment"}},
+			expectedErr: "document selectors are only supported for yaml files",
+		},
+		{
+			name:         "invalid env name",
+			substitution: Substitution{Name: "a", File: ".env", Path: "MY KEY", Value: []byte(`1`)},
+			expectedErr:  "invalid variable name MY KEY",
+		},
+		{
+			name:         "unsupported format",
+			substitution: Substitution{Name: "a", File: ".env", Path: "KEY", Value: []byte(`1`), Format: "ini"},
+			expectedErr:  "unsupported format",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorContains(t, Apply(root, Substitutions{tc.substitution}), tc.expectedErr)
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	for file, format := range map[string]string{
+		"deploy.yaml":            FormatYAML,
+		"manifest":               FormatYAML,
+		"config/App.JSON":        FormatJSON,
+		"config.toml":            FormatTOML,
+		".env":                   FormatEnv,
+		".env.production":        FormatEnv,
+		"local.env":              FormatEnv,
+		"application.properties": FormatProperties,
+	} {
+		assert.Equal(t, format, DetectFormat(file), file)
+	}
+}
@@ -0,0 +1,342 @@
+package substitute
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// tomlEditor edits TOML files in place. The file is parsed with go-toml and only the text of replaced values changes, so comments, ordering
+// and formatting of the rest of the file are kept. Keys which don't exist yet are added to the deepest
+// existing table containing them.
+type tomlEditor struct {
+	content string
+}
+
+// tomlEntry is a key/value pair of a TOML file with the offsets of its value.
+type tomlEntry struct {
+	path       []segment
+	start, end int
+}
+
+// tomlTable is a table of a TOML file with the offset new keys are inserted at.
+type tomlTable struct {
+	path     []segment
+	insertAt int
+}
+
+func newTOMLEditor(content []byte) (*tomlEditor, error) {
+	if err := toml.Unmarshal(content, &map[string]any{}); err != nil {
+		return nil, err
+	}
+
+	return &tomlEditor{content: string(content)}, nil
+}
+
+func (e *tomlEditor) set(s Substitution) error {
+	target, err := parsePath(s.Path)
+	if err != nil {
+		return err
+	}
+
+	value, err := tomlValue(s.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+
+	entries, tables, err := scanTOML(e.content)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		switch {
+		case equalPath(entry.path, target):
+			e.content = e.content[:entry.start] + value + e.content[entry.end:]
+
+			return nil
+		case hasPrefix(target, entry.path):
+			return fmt.Errorf("cannot set %s inside the value of %s, replace the whole value instead", s.Path, pathString(entry.path))
+		}
+	}
+
+	var table *tomlTable
+	for i := range tables {
+		if hasPrefix(target, tables[i].path) && (table == nil || len(tables[i].path) > len(table.path)) {
+			table = &tables[i]
+		}
+	}
+
+	rest := target[len(table.path):]
+	if len(rest) == 0 {
+		return fmt.Errorf("%s is a table", s.Path)
+	}
+
+	keys := make([]string, 0, len(rest))
+	for _, seg := range rest {
+		if !seg.isKey {
+			return fmt.Errorf("cannot create list element of %s", s.Path)
+		}
+
+		key, err := tomlKey(seg.key)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+	}
+
+	line := strings.Join(keys, ".") + " = " + value + "\n"
+	if table.insertAt > 0 && e.content[table.insertAt-1] != '\n' {
+		line = "\n" + line
+	}
+
+	e.content = e.content[:table.insertAt] + line + e.content[table.insertAt:]
+
+	return nil
+}
+
+func (e *tomlEditor) bytes() ([]byte, error) {
+	if err := toml.Unmarshal([]byte(e.content), &map[string]any{}); err != nil {
+		return nil, fmt.Errorf("substitutions result in invalid toml: %w", err)
+	}
+
+	return []byte(e.content), nil
+}
+
+// scanTOML returns the key/value pairs and tables of a document parsed with the parser of go-toml. The root
+// table is always the first table.
+func scanTOML(s string) ([]tomlEntry, []tomlTable, error) {
+	p := &unstable.Parser{KeepComments: true}
+	p.Reset([]byte(s))
+
+	var entries []tomlEntry
+
+	tables := []tomlTable{{}}
+	current := 0
+	arrays := map[string]int{}
+
+	// the value of the last entry ends before whatever comes next, a comment, a table or another entry
+	open := -1
+	closeEntry := func(next int) {
+		if open < 0 {
+			return
+		}
+
+		entries[open].end = len(strings.TrimRight(s[:next], " \t\r\n["))
+		tables[current].insertAt = nextLine(s, entries[open].end)
+		open = -1
+	}
+
+	for p.NextExpression() {
+		expr := p.Expression()
+
+		switch expr.Kind {
+		case unstable.Comment:
+			closeEntry(int(expr.Raw.Offset))
+		case unstable.Table, unstable.ArrayTable:
+			keys, first, last := tomlKeys(expr)
+			closeEntry(first)
+
+			path := keySegments(keys)
+			if expr.Kind == unstable.ArrayTable {
+				name := pathString(path)
+				path = append(path, segment{index: arrays[name]})
+				arrays[name]++
+			}
+
+			tables = append(tables, tomlTable{path: path, insertAt: nextLine(s, last)})
+			current = len(tables) - 1
+		case unstable.KeyValue:
+			keys, first, last := tomlKeys(expr)
+			closeEntry(first)
+
+			// the parser made sure the key is followed by =
+			start := skipSpace(s, skipSpace(s, last)+1)
+			path := append(append([]segment{}, tables[current].path...), keySegments(keys)...)
+			entries = append(entries, tomlEntry{path: path, start: start})
+			open = len(entries) - 1
+
+			if comment := expr.Next(); comment.Valid() && comment.Kind == unstable.Comment {
+				closeEntry(int(comment.Raw.Offset))
+			}
+		}
+	}
+
+	if err := p.Error(); err != nil {
+		return nil, nil, err
+	}
+
+	closeEntry(len(s))
+
+	return entries, tables, nil
+}
+
+// tomlKeys returns the parts of the key of a table or key/value pair with the offsets the key starts and
+// ends at.
+func tomlKeys(expr *unstable.Node) ([]string, int, int) {
+	var (
+		keys        []string
+		first, last = -1, 0
+	)
+
+	it := expr.Key()
+	for it.Next() {
+		key := it.Node()
+		keys = append(keys, string(key.Data))
+
+		if first < 0 {
+			first = int(key.Raw.Offset)
+		}
+
+		last = int(key.Raw.Offset + key.Raw.Length)
+	}
+
+	return keys, first, last
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+
+	return i
+}
+
+func nextLine(s string, i int) int {
+	j := strings.IndexByte(s[i:], '\n')
+	if j < 0 {
+		return len(s)
+	}
+
+	return i + j + 1
+}
+
+// tomlValue encodes a JSON value as TOML with the encoder of go-toml. Objects become inline tables.
+func tomlValue(value json.RawMessage) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return "", err
+	}
+
+	v, err := tomlCompatible(v)
+	if err != nil {
+		return "", err
+	}
+
+	line, err := encodeTOML(map[string]any{"v": v})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(line, "v = "), nil
+}
+
+// tomlCompatible converts the numbers of a decoded JSON value, TOML distinguishes integers and floats. Null
+// has no TOML representation.
+func tomlCompatible(v any) (any, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, errors.New("toml does not support null values")
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i, nil
+		}
+
+		return x.Float64()
+	case []any:
+		for i := range x {
+			var err error
+			if x[i], err = tomlCompatible(x[i]); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]any:
+		for k := range x {
+			var err error
+			if x[k], err = tomlCompatible(x[k]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// tomlKey returns the key quoted if it isn't a bare key.
+func tomlKey(key string) (string, error) {
+	line, err := encodeTOML(map[string]any{key: true})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(line, " = true"), nil
+}
+
+// encodeTOML encodes a document with a single key/value pair and returns its line.
+func encodeTOML(doc map[string]any) (string, error) {
+	b := &strings.Builder{}
+
+	encoder := toml.NewEncoder(b)
+	encoder.SetTablesInline(true)
+
+	if err := encoder.Encode(doc); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func keySegments(keys []string) []segment {
+	segments := make([]segment, 0, len(keys))
+	for _, k := range keys {
+		segments = append(segments, segment{key: k, isKey: true})
+	}
+
+	return segments
+}
+
+func equalPath(a, b []segment) bool {
+	return len(a) == len(b) && hasPrefix(a, b)
+}
+
+func hasPrefix(path, prefix []segment) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func pathString(path []segment) string {
+	b := &strings.Builder{}
+	for _, seg := range path {
+		if !seg.isKey {
+			fmt.Fprintf(b, "[%d]", seg.index)
+
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+
+		b.WriteString(seg.key)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,327 @@
+package substitute
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-component-model/ocm-controller/pkg/configdata"
+)
+
+const defaultIndent = 2
+
+var errNoDocument = errors.New("no document matches the selector")
+
+// yamlEditor edits YAML and JSON files. JSON is a subset of YAML, so both are parsed into YAML nodes which
+// keep the order of keys and, for YAML, comments.
+type yamlEditor struct {
+	docs   []*yaml.Node
+	json   bool
+	indent string
+}
+
+func newYAMLEditor(content []byte, isJSON bool) (*yamlEditor, error) {
+	docs, err := parseDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if isJSON && len(docs) != 1 {
+		return nil, errors.New("json files must contain exactly one document")
+	}
+
+	return &yamlEditor{docs: docs, json: isJSON, indent: detectIndent(content)}, nil
+}
+
+func (e *yamlEditor) set(s Substitution) error {
+	value, err := valueNode(s.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+
+	targets := e.docs[:1]
+	if s.Document != nil {
+		targets = nil
+		for _, doc := range e.docs {
+			if matchesSelector(doc, s.Document) {
+				targets = append(targets, doc)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return errNoDocument
+	}
+
+	for _, doc := range targets {
+		if err := setPath(doc, s.Path, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *yamlEditor) bytes() ([]byte, error) {
+	if e.json {
+		return encodeJSON(e.docs[0], e.indent)
+	}
+
+	return encodeYAML(e.docs)
+}
+
+func readDocuments(root, file string) ([]*yaml.Node, error) {
+	path, err := securejoin.SecureJoin(root, file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := parseDocuments(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	return docs, nil
+}
+
+func parseDocuments(content []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if len(docs) == 0 {
+		docs = append(docs, &yaml.Node{Kind: yaml.DocumentNode})
+	}
+
+	return docs, nil
+}
+
+func encodeYAML(docs []*yaml.Node) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(defaultIndent)
+
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeJSON encodes the document with the given indentation, keeping the order of keys.
+func encodeJSON(doc *yaml.Node, indent string) ([]byte, error) {
+	var value any = orderedValue{}
+	if len(doc.Content) > 0 {
+		value = orderedValue{node: doc.Content[0]}
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// orderedValue marshals a YAML node to JSON without sorting the keys of maps.
+type orderedValue struct {
+	node *yaml.Node
+}
+
+func (v orderedValue) MarshalJSON() ([]byte, error) {
+	node := v.node
+	if node == nil {
+		return []byte("null"), nil
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	buf := &bytes.Buffer{}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeJSON(buf, node.Content[i].Value); err != nil {
+				return nil, err
+			}
+
+			buf.WriteByte(':')
+
+			if err := writeJSON(buf, orderedValue{node: node.Content[i+1]}); err != nil {
+				return nil, err
+			}
+		}
+
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeJSON(buf, orderedValue{node: item}); err != nil {
+				return nil, err
+			}
+		}
+
+		buf.WriteByte(']')
+	default:
+		if (node.Tag == "!!int" || node.Tag == "!!float") && json.Valid([]byte(node.Value)) {
+			// keep numbers as written, decoding would turn 1.0 into 1
+			buf.WriteString(node.Value)
+
+			break
+		}
+
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		if err := writeJSON(buf, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, value any) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+
+	// Encode terminates every value with a newline
+	buf.Truncate(buf.Len() - 1)
+
+	return nil
+}
+
+// detectIndent returns the indentation of the first indented line, defaulting to two spaces.
+func detectIndent(content []byte) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+
+	return strings.Repeat(" ", defaultIndent)
+}
+
+// valueNode converts a JSON value into a YAML node using block style.
+func valueNode(value json.RawMessage) (*yaml.Node, error) {
+	if len(value) == 0 {
+		value = json.RawMessage("null")
+	}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(value, doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, errors.New("empty value")
+	}
+
+	resetStyle(doc.Content[0])
+
+	return doc.Content[0], nil
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetStyle(n)
+	}
+}
+
+func matchesSelector(doc *yaml.Node, selector *configdata.DocumentSelector) bool {
+	if selector == nil {
+		return true
+	}
+
+	if len(doc.Content) == 0 {
+		return false
+	}
+
+	root := doc.Content[0]
+	if selector.Kind != "" && scalar(lookup(root, "kind")) != selector.Kind {
+		return false
+	}
+
+	if selector.Name != "" && scalar(lookup(lookup(root, "metadata"), "name")) != selector.Name {
+		return false
+	}
+
+	return true
+}
+
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return node.Value
+}
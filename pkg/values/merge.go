@@ -0,0 +1,178 @@
+package values
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// Merge strategies define how a layer of values is combined with the values of the layers before it.
+const (
+	// StrategyReplace replaces the value of every top level key of the layer without merging nested values.
+	StrategyReplace = "Replace"
+	// StrategyDeepMerge merges maps recursively, lists and scalars of the layer replace existing ones.
+	StrategyDeepMerge = "DeepMerge"
+	// StrategyListMergeByKey merges maps like StrategyDeepMerge. Lists of maps are merged by a key, elements
+	// with the same key are deep merged and new elements are appended.
+	StrategyListMergeByKey = "ListMergeByKey"
+)
+
+// DefaultMergeKey identifies list elements if no key is given for StrategyListMergeByKey.
+const DefaultMergeKey = "name"
+
+// Layer is a set of values and the strategy used to merge it.
+type Layer struct {
+	Values   map[string]any
+	Strategy string
+	MergeKey string
+}
+
+// Merge combines the layers in order, later layers take precedence. The layers aren't modified.
+func Merge(layers ...Layer) (map[string]any, error) {
+	result := map[string]any{}
+
+	for i, layer := range layers {
+		var err error
+		if result, err = mergeLayer(result, layer); err != nil {
+			return nil, fmt.Errorf("failed to merge values layer %d: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+func mergeLayer(dst map[string]any, layer Layer) (map[string]any, error) {
+	src := deepCopy(layer.Values).(map[string]any)
+	if src == nil {
+		return dst, nil
+	}
+
+	switch layer.Strategy {
+	case StrategyReplace:
+		for k, v := range src {
+			dst[k] = v
+		}
+
+		return dst, nil
+	case "", StrategyDeepMerge:
+		return mergeMaps(dst, src, ""), nil
+	case StrategyListMergeByKey:
+		key := layer.MergeKey
+		if key == "" {
+			key = DefaultMergeKey
+		}
+
+		return mergeMaps(dst, src, key), nil
+	default:
+		return nil, fmt.Errorf("unknown merge strategy %s", layer.Strategy)
+	}
+}
+
+// mergeMaps merges src into dst. If key is set, lists of maps are merged by the value of key.
+func mergeMaps(dst, src map[string]any, key string) map[string]any {
+	for k, v := range src {
+		dst[k] = mergeValues(dst[k], v, key)
+	}
+
+	return dst
+}
+
+func mergeValues(dst, src any, key string) any {
+	switch s := src.(type) {
+	case map[string]any:
+		if d, ok := dst.(map[string]any); ok {
+			return mergeMaps(d, s, key)
+		}
+	case []any:
+		if d, ok := dst.([]any); ok && key != "" {
+			if merged, ok := mergeLists(d, s, key); ok {
+				return merged
+			}
+		}
+	}
+
+	return src
+}
+
+// mergeLists merges the elements of src into dst by key. It reports false if an element of either list
+// isn't a map containing the key, in which case src replaces dst.
+func mergeLists(dst, src []any, key string) ([]any, bool) {
+	index := make(map[string]int, len(dst))
+
+	for i, item := range dst {
+		id, ok := elementKey(item, key)
+		if !ok {
+			return nil, false
+		}
+
+		index[id] = i
+	}
+
+	for _, item := range src {
+		id, ok := elementKey(item, key)
+		if !ok {
+			return nil, false
+		}
+
+		if i, found := index[id]; found {
+			dst[i] = mergeMaps(dst[i].(map[string]any), item.(map[string]any), key)
+
+			continue
+		}
+
+		index[id] = len(dst)
+		dst = append(dst, item)
+	}
+
+	return dst, true
+}
+
+func elementKey(item any, key string) (string, bool) {
+	m, ok := item.(map[string]any)
+	if !ok {
+		return "", false
+	}
+
+	value, ok := m[key]
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprint(value), true
+}
+
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if v == nil {
+			return map[string]any(nil)
+		}
+
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = deepCopy(item)
+		}
+
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = deepCopy(item)
+		}
+
+		return result
+	default:
+		return v
+	}
+}
+
+// Digest returns the sha256 digest of the JSON encoding of the values. Keys of maps are sorted by the
+// encoding, so equal values always have the same digest.
+func Digest(values map[string]any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
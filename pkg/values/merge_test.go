@@ -0,0 +1,109 @@
+package values
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	base := map[string]any{
+		"replicas": 1,
+		"image":    map[string]any{"repository": "ghcr.io/app", "tag": "1.0.0"},
+		"env": []any{
+			map[string]any{"name": "LOG_LEVEL", "value": "info"},
+			map[string]any{"name": "REGION", "value": "eu"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		layer    Layer
+		expected map[string]any
+		err      string
+	}{
+		{
+			name:  "deep merge by default",
+			layer: Layer{Values: map[string]any{"image": map[string]any{"tag": "2.0.0"}, "env": []any{}}},
+			expected: map[string]any{
+				"replicas": 1,
+				"image":    map[string]any{"repository": "ghcr.io/app", "tag": "2.0.0"},
+				"env":      []any{},
+			},
+		},
+		{
+			name:  "replace",
+			layer: Layer{Values: map[string]any{"image": map[string]any{"tag": "2.0.0"}}, Strategy: StrategyReplace},
+			expected: map[string]any{
+				"replicas": 1,
+				"image":    map[string]any{"tag": "2.0.0"},
+				"env":      base["env"],
+			},
+		},
+		{
+			name: "list merge by key",
+			layer: Layer{
+				Values: map[string]any{"env": []any{
+					map[string]any{"name": "LOG_LEVEL", "value": "debug"},
+					map[string]any{"name": "ZONE", "value": "a"},
+				}},
+				Strategy: StrategyListMergeByKey,
+			},
+			expected: map[string]any{
+				"replicas": 1,
+				"image":    base["image"],
+				"env": []any{
+					map[string]any{"name": "LOG_LEVEL", "value": "debug"},
+					map[string]any{"name": "REGION", "value": "eu"},
+					map[string]any{"name": "ZONE", "value": "a"},
+				},
+			},
+		},
+		{
+			name: "list merge with custom key falls back to replace",
+			layer: Layer{
+				Values:   map[string]any{"env": []any{map[string]any{"name": "ZONE", "value": "a"}}},
+				Strategy: StrategyListMergeByKey,
+				MergeKey: "id",
+			},
+			expected: map[string]any{
+				"replicas": 1,
+				"image":    base["image"],
+				"env":      []any{map[string]any{"name": "ZONE", "value": "a"}},
+			},
+		},
+		{
+			name:  "unknown strategy",
+			layer: Layer{Values: map[string]any{}, Strategy: "Shallow"},
+			err:   "failed to merge values layer 1: unknown merge strategy Shallow",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Merge(Layer{Values: base}, tc.layer)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+
+	assert.Equal(t, "info", base["env"].([]any)[0].(map[string]any)["value"], "layers must not be modified")
+}
+
+func TestDigest(t *testing.T) {
+	a, err := Digest(map[string]any{"a": 1, "b": map[string]any{"c": true}})
+	require.NoError(t, err)
+
+	b, err := Digest(map[string]any{"b": map[string]any{"c": true}, "a": 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", a)
+}
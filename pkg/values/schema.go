@@ -0,0 +1,118 @@
+package values
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// schemaURL is the location the schema is compiled from. Only references within the schema, e.g. to
+// #/$defs/port, and the meta schemas of the drafts can be resolved, nothing is loaded from files or the network.
+const schemaURL = "values.schema.json"
+
+// Violation is a value which doesn't match the schema, identified by its JSON pointer, e.g. /image/tag. The
+// pointer of the root is empty.
+type Violation struct {
+	Pointer string
+	Message string
+}
+
+// ValidationError is returned for values which don't match the schema. It holds every violation.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		pointer := v.Pointer
+		if pointer == "" {
+			pointer = "(root)"
+		}
+
+		messages = append(messages, pointer+": "+v.Message)
+	}
+
+	return "values don't match the schema: " + strings.Join(messages, "; ")
+}
+
+// Validate validates the values against the JSON schema. Drafts 4, 6, 7, 2019-09 and 2020-12 are supported,
+// selected by the $schema of the schema, 2020-12 if it's not set. A *ValidationError lists every violation.
+func Validate(schema []byte, values map[string]any) error {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	// the values are validated as JSON, so numbers are compared exactly
+	content, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal values: %w", err)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal values: %w", err)
+	}
+
+	err = compiled.Validate(instance)
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	result := &ValidationError{}
+	result.add(validationErr)
+
+	// causes aren't ordered, sort them to report the same violations the same way every time
+	slices.SortStableFunc(result.Violations, func(a, b Violation) int {
+		return cmp.Or(cmp.Compare(a.Pointer, b.Pointer), cmp.Compare(a.Message, b.Message))
+	})
+
+	return result
+}
+
+var printer = message.NewPrinter(language.English)
+
+// add adds the violations of the error. Errors with causes, like a failed $ref or allOf, are reported by
+// their causes.
+func (e *ValidationError) add(err *jsonschema.ValidationError) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			e.add(cause)
+		}
+
+		return
+	}
+
+	var pointer strings.Builder
+	for _, token := range err.InstanceLocation {
+		pointer.WriteString("/" + pointerEscaper.Replace(token))
+	}
+
+	e.Violations = append(e.Violations, Violation{Pointer: pointer.String(), Message: err.ErrorKind.LocalizedString(printer)})
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
@@ -0,0 +1,56 @@
+package values
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	schema := []byte(`{
+  "$defs": {"port": {"type": "integer", "minimum": 1}},
+  "type": "object",
+  "required": ["name"],
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string"},
+    "replicas": {"type": "integer", "maximum": 5},
+    "ports": {"type": "array", "items": {"$ref": "#/$defs/port"}},
+    "image": {"type": "object", "properties": {"tag": {"type": "string"}}}
+  }
+}`)
+
+	require.NoError(t, Validate(schema, map[string]any{"name": "podinfo", "replicas": 3, "ports": []any{80}}))
+
+	err := Validate(schema, map[string]any{
+		"replicas": 7,
+		"ports":    []any{80, 0},
+		"image":    map[string]any{"tag": 1},
+		"extra":    true,
+	})
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []Violation{
+		{Pointer: "", Message: "additional properties 'extra' not allowed"},
+		{Pointer: "", Message: "missing property 'name'"},
+		{Pointer: "/image/tag", Message: "got number, want string"},
+		{Pointer: "/ports/1", Message: "minimum: got 0, want 1"},
+		{Pointer: "/replicas", Message: "maximum: got 7, want 5"},
+	}, validationErr.Violations)
+	assert.ErrorContains(t, err, "(root): missing property 'name'")
+}
+
+func TestValidateDrafts(t *testing.T) {
+	draft7 := []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "properties": {"tag": {"type": "string"}}}`)
+
+	err := Validate(draft7, map[string]any{"tag": 1})
+	assert.ErrorContains(t, err, "/tag: got number, want string")
+
+	remote := []byte(`{"$ref": "https://example.com/values.schema.json"}`)
+
+	err = Validate(remote, map[string]any{})
+	assert.ErrorContains(t, err, "invalid schema")
+}